@@ -0,0 +1,61 @@
+package blockstore_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+func TestOpenArchive(t *testing.T) {
+	b := testutil.NewLedger(t)
+	b.AddBlock(1, 0, []blockstore.Entry{{NumHashes: 1, Hash: solana.Hash{1}}})
+	path := b.Path()
+	// Drop the info logs written by the builder.
+	logs, err := filepath.Glob(filepath.Join(path, "LOG*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, log := range logs {
+		if err := os.Remove(log); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := listDir(t, path)
+
+	db, err := blockstore.OpenArchive(path)
+	if err != nil {
+		t.Fatalf("cannot open archive: %s", err)
+	}
+	block, err := db.GetBlock(1)
+	if err != nil {
+		t.Errorf("GetBlock: %s", err)
+	} else if block.BlockHash != (solana.Hash{1}) {
+		t.Errorf("block hash %s", block.BlockHash)
+	}
+	db.Close()
+
+	after := listDir(t, path)
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("archive open changed the ledger directory from %v to %v", before, after)
+	}
+}
+
+func listDir(t *testing.T, path string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	sort.Strings(names)
+	return names
+}
@@ -8,9 +8,12 @@ package blockstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
+	"sync"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -20,15 +23,43 @@ import (
 )
 
 // DB wraps a RocksDB database handle.
+//
+// DB is safe for concurrent use by multiple goroutines.
+// Iterators returned by DB are not; each must be used and closed by a single goroutine.
+// Methods returning a *grocksdb.Slice leave freeing it to the caller,
+// all other results are copied into Go memory.
 type DB struct {
 	db *grocksdb.DB
 
+	cfDefault     *grocksdb.ColumnFamilyHandle
 	cfMeta        *grocksdb.ColumnFamilyHandle
 	cfRoot        *grocksdb.ColumnFamilyHandle
 	cfDeadSlots   *grocksdb.ColumnFamilyHandle
 	cfBlockHeight *grocksdb.ColumnFamilyHandle
 	cfDataShred   *grocksdb.ColumnFamilyHandle
 	cfCodeShred   *grocksdb.ColumnFamilyHandle
+	cfTxStatus    *grocksdb.ColumnFamilyHandle
+	cfTxStatusIdx *grocksdb.ColumnFamilyHandle
+	cfTxMemos     *grocksdb.ColumnFamilyHandle
+	cfProgramCost *grocksdb.ColumnFamilyHandle
+	cfOptimistic  *grocksdb.ColumnFamilyHandle
+	cfMerkleRoot  *grocksdb.ColumnFamilyHandle
+	cfAddrSigs    *grocksdb.ColumnFamilyHandle
+	cfBlockTime   *grocksdb.ColumnFamilyHandle
+	cfRewards     *grocksdb.ColumnFamilyHandle
+	cfErasureMeta *grocksdb.ColumnFamilyHandle
+
+	cfs           map[string]*grocksdb.ColumnFamilyHandle // all opened column families
+	log           Logger
+	readAheadSize uint64
+	metaCache     *slotMetaCache // nil if disabled
+	tracer        Tracer
+	limiter       *readLimiter // nil if reads are not throttled
+
+	// Read options shared by point lookups and iterators without bounds.
+	readOpts *grocksdb.ReadOptions
+	iterOpts *grocksdb.ReadOptions
+	snapshot *grocksdb.Snapshot // set on views created by NewSnapshot
 }
 
 // Column families
@@ -40,6 +71,17 @@ const (
 	CfBlockHeight = "block_height"
 	CfDataShred   = "data_shred"
 	CfCodeShred   = "code_shred"
+
+	CfTransactionStatus      = "transaction_status"
+	CfTransactionStatusIndex = "transaction_status_index"
+	CfTransactionMemos       = "transaction_memos"
+	CfProgramCosts           = "program_costs"
+	CfOptimisticSlots        = "optimistic_slots"
+	CfMerkleRootMeta         = "merkle_root_meta"
+	CfAddressSignatures      = "address_signatures"
+	CfBlockTime              = "blocktime"
+	CfRewards                = "rewards"
+	CfErasureMeta            = "erasure_meta"
 )
 
 // ErrNotFound is returned when no row is found.
@@ -49,25 +91,39 @@ var ErrDeadSlot = errors.New("dead slot")
 
 var ErrInvalidShredData = errors.New("invalid shred data")
 
+// ErrMissingShreds is returned when data shreds required to decode a data block are missing.
+//
+// It matches ErrInvalidShredData with errors.Is.
+type ErrMissingShreds struct {
+	Slot    uint64
+	Missing []uint64 // indexes of the missing data shreds
+}
+
+func (e *ErrMissingShreds) Error() string {
+	return fmt.Sprintf("%s: missing %d shreds for slot %d, indexes %v", ErrInvalidShredData, len(e.Missing), e.Slot, e.Missing)
+}
+
+func (e *ErrMissingShreds) Unwrap() error {
+	return ErrInvalidShredData
+}
+
+// ErrShredVersionMismatch is returned when a shred does not have the requested shred version.
+var ErrShredVersionMismatch = errors.New("shred version mismatch")
+
+// ErrInvalidKey is returned when a RocksDB key has an unexpected format.
+var ErrInvalidKey = errors.New("invalid key")
+
+// ErrColumnFamilyUnavailable is returned when the ledger was created
+// by a Solana version lacking the requested column family.
+var ErrColumnFamilyUnavailable = errors.New("column family unavailable")
+
 // OpenReadOnly attaches to a blockstore in read-only mode.
 //
 // Attaching to running validators is supported but the DB will only be a
 // point-in-time view at the time of attaching.
-func OpenReadOnly(path string) (*DB, error) {
-	opts, cfNames, cfOpts := getOpts()
-
-	rawDB, cfHandles, err := grocksdb.OpenDbForReadOnlyColumnFamilies(
-		opts,
-		path,
-		cfNames,
-		cfOpts,
-		/*errorIfWalFileExists*/ false,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return newDB(rawDB, cfHandles)
+// Use WithRequireFlushed to refuse ledgers with a write-ahead log.
+func OpenReadOnly(path string, options ...Option) (*DB, error) {
+	return OpenReadOnlyWithOptions(path, collectOptions(options))
 }
 
 // OpenSecondary attaches to a blockstore in secondary mode.
@@ -76,8 +132,12 @@ func OpenReadOnly(path string) (*DB, error) {
 // Unlike OpenReadOnly, allows the user to catch up the DB using DB.TryCatchUpWithPrimary.
 //
 // `secondaryPath` points to a directory where the secondary instance stores its info log.
-func OpenSecondary(path string, secondaryPath string) (*DB, error) {
-	opts, cfNames, cfOpts := getOpts()
+func OpenSecondary(path string, secondaryPath string, options ...Option) (*DB, error) {
+	o := collectOptions(options)
+	opts, cfNames, cfOpts, err := getOpts(path, &o)
+	if err != nil {
+		return nil, err
+	}
 
 	rawDB, cfHandles, err := grocksdb.OpenDbAsSecondaryColumnFamilies(
 		opts,
@@ -90,66 +150,129 @@ func OpenSecondary(path string, secondaryPath string) (*DB, error) {
 		return nil, err
 	}
 
-	return newDB(rawDB, cfHandles)
+	return newDB(rawDB, cfNames, cfHandles, &o)
 }
 
-var columnFamilyNames = []string{
+// Column families present in all supported Solana versions.
+// Opening a ledger without these fails.
+var requiredColumnFamilyNames = []string{
 	CfDefault,
 	CfMeta,
 	CfRoot,
 	CfDeadSlots,
-	CfBlockHeight,
 	CfDataShred,
 	CfCodeShred,
 }
 
-func getOpts() (opts *grocksdb.Options, cfNames []string, cfOpts []*grocksdb.Options) {
-	opts = grocksdb.NewDefaultOptions()
-	cfNames = columnFamilyNames
-	cfOpts = []*grocksdb.Options{
-		grocksdb.NewDefaultOptions(), // CfDefault
-		grocksdb.NewDefaultOptions(), // CfMeta
-		grocksdb.NewDefaultOptions(), // CfRoot
-		grocksdb.NewDefaultOptions(), // CfDeadSlots
-		grocksdb.NewDefaultOptions(), // CfBlockHeight
-		grocksdb.NewDefaultOptions(), // CfDataShred
-		grocksdb.NewDefaultOptions(), // CfCodeShred
+// getOpts returns the options for opening the column families that exist in the ledger.
+//
+// Column families unknown to this package are opened as well, for use with GetRaw.
+func getOpts(path string, o *OpenOptions) (opts *grocksdb.Options, cfNames []string, cfOpts []*grocksdb.Options, err error) {
+	opts, err = o.dbOptions()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	present, err := grocksdb.ListColumnFamilies(opts, path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	presentSet := make(map[string]bool, len(present))
+	for _, name := range present {
+		presentSet[name] = true
+	}
+	for _, name := range requiredColumnFamilyNames {
+		if !presentSet[name] {
+			return nil, nil, nil, fmt.Errorf("%w: %s", ErrColumnFamilyUnavailable, name)
+		}
+	}
+	var cache *grocksdb.Cache
+	if o.BlockCacheSize > 0 {
+		cache = grocksdb.NewLRUCache(o.BlockCacheSize)
+	}
+	for _, name := range present {
+		cfNames = append(cfNames, name)
+		cfOpts = append(cfOpts, o.cfOptions(name, cache))
 	}
 	return
 }
 
-func newDB(rawDB *grocksdb.DB, cfHandles []*grocksdb.ColumnFamilyHandle) (*DB, error) {
-	if len(columnFamilyNames) != len(cfHandles) {
+func newDB(rawDB *grocksdb.DB, cfNames []string, cfHandles []*grocksdb.ColumnFamilyHandle, o *OpenOptions) (*DB, error) {
+	if len(cfNames) != len(cfHandles) {
 		rawDB.Close()
 		return nil, fmt.Errorf("unexpected number of column families: %d", len(cfHandles))
 	}
 	db := &DB{
 		db:            rawDB,
-		cfMeta:        cfHandles[1],
-		cfRoot:        cfHandles[2],
-		cfDeadSlots:   cfHandles[3],
-		cfBlockHeight: cfHandles[4],
-		cfDataShred:   cfHandles[5],
-		cfCodeShred:   cfHandles[6],
+		cfs:           make(map[string]*grocksdb.ColumnFamilyHandle, len(cfNames)),
+		log:           o.logger(),
+		readAheadSize: o.ReadAheadSize,
+		limiter:       newReadLimiter(o.RateLimit),
+		tracer:        o.tracer(),
+		readOpts:      grocksdb.NewDefaultReadOptions(),
+	}
+	db.iterOpts = db.newIterReadOptions()
+	if o.SlotMetaCacheSize > 0 {
+		db.metaCache = newSlotMetaCache(o.SlotMetaCacheSize)
+	}
+	handles := map[string]**grocksdb.ColumnFamilyHandle{
+		CfDefault:                &db.cfDefault,
+		CfMeta:                   &db.cfMeta,
+		CfRoot:                   &db.cfRoot,
+		CfDeadSlots:              &db.cfDeadSlots,
+		CfBlockHeight:            &db.cfBlockHeight,
+		CfDataShred:              &db.cfDataShred,
+		CfCodeShred:              &db.cfCodeShred,
+		CfTransactionStatus:      &db.cfTxStatus,
+		CfTransactionStatusIndex: &db.cfTxStatusIdx,
+		CfTransactionMemos:       &db.cfTxMemos,
+		CfProgramCosts:           &db.cfProgramCost,
+		CfOptimisticSlots:        &db.cfOptimistic,
+		CfMerkleRootMeta:         &db.cfMerkleRoot,
+		CfAddressSignatures:      &db.cfAddrSigs,
+		CfBlockTime:              &db.cfBlockTime,
+		CfRewards:                &db.cfRewards,
+		CfErasureMeta:            &db.cfErasureMeta,
+	}
+	for i, name := range cfNames {
+		db.cfs[name] = cfHandles[i]
+		if handle, ok := handles[name]; ok {
+			*handle = cfHandles[i]
+		}
 	}
 	return db, nil
 }
 
+// requireCF returns ErrColumnFamilyUnavailable if the given column family
+// is missing from the ledger.
+func requireCF(cf *grocksdb.ColumnFamilyHandle, name string) error {
+	if cf == nil {
+		return fmt.Errorf("%w: %s", ErrColumnFamilyUnavailable, name)
+	}
+	return nil
+}
+
 // TryCatchUpWithPrimary updates the client's view of the database with the latest information.
 //
 // Only works with DB opened using OpenSecondary.
 func (d *DB) TryCatchUpWithPrimary() error {
+	// Slot metas might have changed.
+	d.metaCache.purge()
 	return d.db.TryCatchUpWithPrimary()
 }
 
 // Close releases the RocksDB client.
 func (d *DB) Close() {
 	d.db.Close()
+	d.readOpts.Destroy()
+	d.iterOpts.Destroy()
 }
 
 // MaxRoot returns the last known root slot.
+//
+// Returns ErrNotFound if there are no roots.
+// A ledger whose only root is slot 0 returns 0 and no error.
 func (d *DB) MaxRoot() (uint64, error) {
-	opts := grocksdb.NewDefaultReadOptions()
+	opts := d.readOpts
 	iter := d.db.NewIteratorCF(opts, d.cfRoot)
 	defer iter.Close()
 	iter.SeekToLast()
@@ -159,19 +282,105 @@ func (d *DB) MaxRoot() (uint64, error) {
 	return ParseSlotKey(iter.Key().Data())
 }
 
-// GetBlockHeight returns the last known root slot.
+// MaxRootOr returns the last known root slot, or def if there are no roots.
+func (d *DB) MaxRootOr(def uint64) uint64 {
+	root, err := d.MaxRoot()
+	if err != nil {
+		return def
+	}
+	return root
+}
+
+// MinRoot returns the first known root slot.
+//
+// Returns ErrNotFound if there are no roots.
+func (d *DB) MinRoot() (uint64, error) {
+	opts := d.readOpts
+	iter := d.db.NewIteratorCF(opts, d.cfRoot)
+	defer iter.Close()
+	iter.SeekToFirst()
+	if !iter.Valid() {
+		return 0, ErrNotFound
+	}
+	return ParseSlotKey(iter.Key().Data())
+}
+
+// GetLowestCleanupSlot returns the lowest slot not purged by ledger cleanup,
+// the lower bound of the slots with data in the ledger.
+//
+// The validator does not persist its lowest cleanup slot.
+// Like Blockstore::lowest_slot, this returns the lowest slot other than the genesis slot
+// that has received shreds.
+// Returns ErrNotFound if no such slot exists.
+func (d *DB) GetLowestCleanupSlot() (uint64, error) {
+	iter := d.IterSlotMetas(nil)
+	defer iter.Close()
+	start := MakeSlotKey(1)
+	for iter.Seek(start[:]); iter.Valid(); iter.Next() {
+		slot, meta, err := iter.SlotMeta()
+		if err != nil {
+			return 0, err
+		}
+		if meta.Received > 0 {
+			return slot, nil
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+	return 0, ErrNotFound
+}
+
+// RootSlotRange returns the first and last known root slots,
+// using a single iterator for a consistent view.
+//
+// Returns ErrNotFound if there are no roots.
+func (d *DB) RootSlotRange() (min, max uint64, err error) {
+	iter := d.db.NewIteratorCF(d.readOpts, d.cfRoot)
+	defer iter.Close()
+	iter.SeekToFirst()
+	if !iter.Valid() {
+		return 0, 0, ErrNotFound
+	}
+	if min, err = ParseSlotKey(iter.Key().Data()); err != nil {
+		return 0, 0, err
+	}
+	iter.SeekToLast()
+	if !iter.Valid() {
+		return 0, 0, ErrNotFound
+	}
+	if max, err = ParseSlotKey(iter.Key().Data()); err != nil {
+		return 0, 0, err
+	}
+	return min, max, nil
+}
+
+// GetBlockHeight returns the block height of the newest slot with a recorded height.
+//
+// See GetBlockHeightAt for the block height of a given slot.
 func (d *DB) GetBlockHeight() (uint64, error) {
-	opts := grocksdb.NewDefaultReadOptions()
+	if err := requireCF(d.cfBlockHeight, CfBlockHeight); err != nil {
+		return 0, err
+	}
+	opts := d.readOpts
 	iter := d.db.NewIteratorCF(opts, d.cfBlockHeight)
 	defer iter.Close()
 	iter.SeekToLast()
 	if !iter.Valid() {
 		return 0, ErrNotFound
 	}
-	return binary.LittleEndian.Uint64(iter.Value().Data()), nil
+	value := iter.Value().Data()
+	d.limiter.charge(len(value))
+	return binary.LittleEndian.Uint64(value), nil
 }
 
+// ParseSlotKey parses a key created by MakeSlotKey.
+//
+// Returns ErrInvalidKey if the key is not 8 bytes long.
 func ParseSlotKey(key []byte) (uint64, error) {
+	if len(key) != 8 {
+		return 0, fmt.Errorf("%w: slot key of length %d", ErrInvalidKey, len(key))
+	}
 	return binary.BigEndian.Uint64(key), nil
 }
 
@@ -188,10 +397,64 @@ func MakeShredKey(slot, index uint64) (key [16]byte) {
 	return
 }
 
+// MakeShredKeyRange returns the key bounds [lo, hi) spanning all shreds of a slot.
+//
+// For slot math.MaxUint64, hi is MakeShredKey(math.MaxUint64, math.MaxUint64),
+// which only excludes a shred index that cannot occur in practice.
+func MakeShredKeyRange(slot uint64) (lo, hi [16]byte) {
+	lo = MakeShredKey(slot, 0)
+	if slot == math.MaxUint64 {
+		hi = MakeShredKey(slot, math.MaxUint64)
+	} else {
+		hi = MakeShredKey(slot+1, 0)
+	}
+	return
+}
+
+// ParseShredKey parses a key created by MakeShredKey.
+//
+// Returns ErrInvalidShredData if the key is not 16 bytes long.
+func ParseShredKey(key []byte) (slot, index uint64, err error) {
+	if len(key) != 16 {
+		return 0, 0, fmt.Errorf("%w: shred key of length %d", ErrInvalidShredData, len(key))
+	}
+	return binary.BigEndian.Uint64(key[0:8]), binary.BigEndian.Uint64(key[8:16]), nil
+}
+
+// MakeErasureSetKey creates the RocksDB key for CfMerkleRootMeta.
+func MakeErasureSetKey(slot uint64, fecSetIndex uint32) (key [12]byte) {
+	binary.BigEndian.PutUint64(key[0:8], slot)
+	binary.BigEndian.PutUint32(key[8:12], fecSetIndex)
+	return
+}
+
+// IterBlockHeights creates an iterator over CfBlockHeight,
+// yielding the block height of each slot.
+//
+// Use MakeSlotKey to seek to a specific slot.
+// Nil options select the defaults.
+//
+// It's the caller's responsibility to close the iterator.
+func (d *DB) IterBlockHeights(o *IterOptions) (BlockHeightIterator, error) {
+	if err := requireCF(d.cfBlockHeight, CfBlockHeight); err != nil {
+		return BlockHeightIterator{}, err
+	}
+	return BlockHeightIterator{newIterBincode[uint64](d, o, d.cfBlockHeight)}, nil
+}
+
 // GetSlotMeta returns the shredding metadata of a given slot.
 func (d *DB) GetSlotMeta(slot uint64) (*SlotMeta, error) {
+	if meta, ok := d.metaCache.get(slot); ok {
+		return meta, nil
+	}
 	key := MakeSlotKey(slot)
-	return GetBincode[SlotMeta](d.db, d.cfMeta, key[:])
+	meta, err := getBincode[SlotMeta](d.db, d.readOpts, d.limiter, d.cfMeta, key[:])
+	if err != nil {
+		return nil, err
+	}
+	meta.Slot = slot
+	d.metaCache.put(meta)
+	return meta, nil
 }
 
 // MultiGetSlotMeta does multiple GetSlotMeta calls.
@@ -201,91 +464,573 @@ func (d *DB) MultiGetSlotMeta(slots ...uint64) ([]*SlotMeta, error) {
 		key := MakeSlotKey(slot)
 		keys[i] = key[:] // heap escape
 	}
-	return MultiGetBincode[SlotMeta](d.db, d.cfMeta, keys...)
+	metas, err := multiGetBincode[SlotMeta](d.db, d.readOpts, d.limiter, d.cfMeta, keys...)
+	if err != nil {
+		return nil, err
+	}
+	for i, meta := range metas {
+		meta.Slot = slots[i]
+	}
+	return metas, nil
+}
+
+// GetSlotChildren returns the slots that chain to the given slot.
+func (d *DB) GetSlotChildren(slot uint64) ([]uint64, error) {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return nil, err
+	}
+	return meta.NextSlots, nil
+}
+
+// WalkChain visits the slot tree rooted at `start` depth-first by following
+// SlotMeta.NextSlots, stopping as soon as fn returns false.
+//
+// Child slots without a slot meta are skipped.
+func (d *DB) WalkChain(start uint64, fn func(*SlotMeta) bool) error {
+	stack := []uint64{start}
+	visited := make(map[uint64]bool)
+	for len(stack) > 0 {
+		slot := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[slot] {
+			continue
+		}
+		visited[slot] = true
+
+		meta, err := d.GetSlotMeta(slot)
+		if errors.Is(err, ErrNotFound) && slot != start {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !fn(meta) {
+			return nil
+		}
+		// Push in reverse so that the first child is visited first.
+		for i := len(meta.NextSlots) - 1; i >= 0; i-- {
+			stack = append(stack, meta.NextSlots[i])
+		}
+	}
+	return nil
+}
+
+// GetForkPath returns the slots from `child` up to `ancestor` (both inclusive)
+// by following SlotMeta.ParentSlot, e.g. to check that a slot descends from a root.
+//
+// Fails if `ancestor` is not an ancestor of `child`
+// or if a slot meta along the path is missing.
+func (d *DB) GetForkPath(child, ancestor uint64) ([]uint64, error) {
+	if child < ancestor {
+		return nil, fmt.Errorf("slot %d cannot descend from later slot %d", child, ancestor)
+	}
+	path := []uint64{child}
+	// Each step decreases the slot, so at most child-ancestor steps are needed.
+	for slot := child; slot != ancestor; {
+		meta, err := d.GetSlotMeta(slot)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get meta of slot %d: %w", slot, err)
+		}
+		parent := meta.ParentSlotOpt()
+		if parent == nil {
+			return nil, fmt.Errorf("parent of slot %d unknown", slot)
+		}
+		if *parent >= slot || *parent < ancestor {
+			return nil, fmt.Errorf("slot %d does not descend from slot %d", child, ancestor)
+		}
+		slot = *parent
+		path = append(path, slot)
+	}
+	return path, nil
 }
 
 // IterSlotMetas creates an iterator over CfMeta.
 //
 // Use MakeSlotKey to seek to a specific slot.
+// Nil options select the defaults.
 //
 // It's the caller's responsibility to close the iterator.
-func (d *DB) IterSlotMetas(opts *grocksdb.ReadOptions) IterBincode[SlotMeta] {
-	if opts == nil {
-		opts = grocksdb.NewDefaultReadOptions()
+func (d *DB) IterSlotMetas(o *IterOptions) SlotMetaIterator {
+	return SlotMetaIterator{newIterBincode[SlotMeta](d, o, d.cfMeta)}
+}
+
+// BoundedIterSlotMetas creates an iterator over the slot metas in the slot range [lo, hi],
+// positioned at the first slot meta.
+//
+// Unlike IterSlotMetas, the iterator cannot move past the range.
+// It's the caller's responsibility to close the iterator.
+func (d *DB) BoundedIterSlotMetas(lo, hi uint64) SlotMetaIterator {
+	opts := d.newIterReadOptions()
+	lower := MakeSlotKey(lo)
+	opts.SetIterateLowerBound(lower[:])
+	if hi < math.MaxUint64 {
+		upper := MakeSlotKey(hi + 1)
+		opts.SetIterateUpperBound(upper[:])
 	}
 	rawIter := d.db.NewIteratorCF(opts, d.cfMeta)
-	return IterBincode[SlotMeta]{Iterator: rawIter}
+	rawIter.SeekToFirst()
+	return SlotMetaIterator{IterBincode[SlotMeta]{Iterator: rawIter, opts: opts, limiter: d.limiter}}
+}
+
+// GetLatestSlotMetas returns up to n slot metas with the highest slot numbers,
+// newest first.
+func (d *DB) GetLatestSlotMetas(n int) ([]*SlotMeta, error) {
+	iter := d.IterSlotMetas(nil)
+	defer iter.Close()
+	var metas []*SlotMeta
+	for iter.SeekToLast(); iter.Valid() && len(metas) < n; iter.Prev() {
+		slot, meta, err := iter.SlotMeta()
+		if err != nil {
+			return nil, fmt.Errorf("invalid slot meta %d: %w", slot, err)
+		}
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
+
+// IterRoots creates an iterator over the rooted slots in the slot range [lo, hi],
+// positioned at the first root.
+//
+// It's the caller's responsibility to close the iterator.
+func (d *DB) IterRoots(lo, hi uint64) RootIterator {
+	opts := d.newIterReadOptions()
+	lower := MakeSlotKey(lo)
+	opts.SetIterateLowerBound(lower[:])
+	if hi < math.MaxUint64 {
+		upper := MakeSlotKey(hi + 1)
+		opts.SetIterateUpperBound(upper[:])
+	}
+	rawIter := d.db.NewIteratorCF(opts, d.cfRoot)
+	rawIter.SeekToFirst()
+	return RootIterator{IterBincode[bool]{Iterator: rawIter, opts: opts, limiter: d.limiter}}
+}
+
+// IsRoot returns whether the given slot is rooted.
+func (d *DB) IsRoot(slot uint64) (bool, error) {
+	opts := d.readOpts
+	key := MakeSlotKey(slot)
+	res, err := d.getCF(opts, d.cfRoot, key[:])
+	if err != nil {
+		return false, err
+	}
+	defer res.Free()
+	return res.Exists(), nil
+}
+
+// GetProgramCost returns the estimated compute unit cost of a program.
+func (d *DB) GetProgramCost(program solana.PublicKey) (uint64, error) {
+	cost, err := getBincode[ProgramCost](d.db, d.readOpts, d.limiter, d.cfProgramCost, program[:])
+	if err != nil {
+		return 0, err
+	}
+	return cost.Cost, nil
+}
+
+// IterProgramCosts creates an iterator over CfProgramCosts.
+//
+// Keys are program IDs (solana.PublicKey).
+// Nil options select the defaults.
+//
+// It's the caller's responsibility to close the iterator.
+func (d *DB) IterProgramCosts(o *IterOptions) (IterBincode[ProgramCost], error) {
+	if err := requireCF(d.cfProgramCost, CfProgramCosts); err != nil {
+		return IterBincode[ProgramCost]{}, err
+	}
+	return newIterBincode[ProgramCost](d, o, d.cfProgramCost), nil
+}
+
+// GetOptimisticSlot returns the optimistic confirmation info of a slot.
+func (d *DB) GetOptimisticSlot(slot uint64) (*OptimisticSlotMeta, error) {
+	key := MakeSlotKey(slot)
+	meta, err := getBincode[OptimisticSlotMeta](d.db, d.readOpts, d.limiter, d.cfOptimistic, key[:])
+	if err != nil {
+		return nil, err
+	}
+	if meta.Version != 0 {
+		return nil, fmt.Errorf("unsupported optimistic slot meta version %d", meta.Version)
+	}
+	meta.Slot = slot
+	return meta, nil
+}
+
+// IterOptimisticSlots creates an iterator over CfOptimisticSlots.
+//
+// Use MakeSlotKey to seek to a specific slot.
+// Nil options select the defaults.
+//
+// It's the caller's responsibility to close the iterator.
+func (d *DB) IterOptimisticSlots(o *IterOptions) (IterBincode[OptimisticSlotMeta], error) {
+	if err := requireCF(d.cfOptimistic, CfOptimisticSlots); err != nil {
+		return IterBincode[OptimisticSlotMeta]{}, err
+	}
+	return newIterBincode[OptimisticSlotMeta](d, o, d.cfOptimistic), nil
+}
+
+// GetLatestOptimisticSlots returns up to n of the most recent optimistically
+// confirmed slots, newest first.
+func (d *DB) GetLatestOptimisticSlots(n int) ([]*OptimisticSlotMeta, error) {
+	iter, err := d.IterOptimisticSlots(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	var metas []*OptimisticSlotMeta
+	for iter.SeekToLast(); iter.Valid() && len(metas) < n; iter.Prev() {
+		slot, err := ParseSlotKey(iter.Key().Data())
+		if err != nil {
+			return nil, err
+		}
+		meta, err := iter.Element()
+		if err != nil {
+			return nil, fmt.Errorf("invalid optimistic slot meta %d: %w", slot, err)
+		}
+		meta.Slot = slot
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
+
+// GetMerkleRootMeta returns the first received Merkle root of an erasure set.
+func (d *DB) GetMerkleRootMeta(slot uint64, fecSetIndex uint64) (*MerkleRootMeta, error) {
+	key := MakeErasureSetKey(slot, uint32(fecSetIndex))
+	return getBincode[MerkleRootMeta](d.db, d.readOpts, d.limiter, d.cfMerkleRoot, key[:])
 }
 
 func (d *DB) IsSlotDead(slot uint64) (bool, error) {
-	opts := grocksdb.NewDefaultReadOptions()
+	opts := d.readOpts
 	key := MakeSlotKey(slot)
-	res, err := d.db.GetCF(opts, d.cfDeadSlots, key[:])
+	res, err := d.getCF(opts, d.cfDeadSlots, key[:])
 	if err != nil {
 		return false, err
 	}
+	defer res.Free()
 	return res.Exists() && bytes.Equal(res.Data(), []byte{1}), nil
 }
 
+// GetDeadSlots returns all slots marked as dead in ascending order.
+func (d *DB) GetDeadSlots() ([]uint64, error) {
+	iter := d.db.NewIteratorCF(d.iterOpts, d.cfDeadSlots)
+	defer iter.Close()
+	var slots []uint64
+	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+		value := iter.Value().Data()
+		d.limiter.charge(len(value))
+		if !bytes.Equal(value, []byte{1}) {
+			continue
+		}
+		slot, err := ParseSlotKey(iter.Key().Data())
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return slots, iter.Err()
+}
+
 // GetDataShred returns the content of a given data shred.
+//
+// The returned slice points into memory owned by RocksDB,
+// which the caller has to release using Free.
+// Prefer GetDataShredBytes unless avoiding the copy matters.
 func (d *DB) GetDataShred(slot, index uint64) (*grocksdb.Slice, error) {
-	opts := grocksdb.NewDefaultReadOptions()
+	opts := d.readOpts
+	key := MakeShredKey(slot, index)
+	return d.getCF(opts, d.cfDataShred, key[:])
+}
+
+// GetDataShredBytes returns a copy of the content of a given data shred.
+//
+// Returns ErrNotFound if the shred does not exist.
+func (d *DB) GetDataShredBytes(slot, index uint64) ([]byte, error) {
 	key := MakeShredKey(slot, index)
-	return d.db.GetCF(opts, d.cfDataShred, key[:])
+	return d.getBytes(d.cfDataShred, key[:])
+}
+
+// MultiGetDataShred returns the contents of multiple data shreds,
+// each identified by a (slot, index) pair, in a single batched lookup.
+//
+// Missing shreds are returned as slices for which Exists() is false.
+// It's the caller's responsibility to free the returned slices, e.g. using grocksdb.Slices.Destroy.
+func (d *DB) MultiGetDataShred(keys ...[2]uint64) ([]*grocksdb.Slice, error) {
+	rawKeys := make([][]byte, len(keys))
+	for i, k := range keys {
+		key := MakeShredKey(k[0], k[1])
+		rawKeys[i] = key[:]
+	}
+	opts := d.readOpts
+	res, err := d.db.MultiGetCF(opts, d.cfDataShred, rawKeys...)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range res {
+		d.limiter.charge(s.Size())
+	}
+	return res, nil
+}
+
+// GetRaw returns the value of a key in any column family present in the ledger,
+// including ones not modeled by this package.
+//
+// Returns ErrColumnFamilyUnavailable if the ledger has no column family of the given name.
+// It's the caller's responsibility to free the returned slice.
+func (d *DB) GetRaw(cf string, key []byte) (*grocksdb.Slice, error) {
+	handle, ok := d.cfs[cf]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrColumnFamilyUnavailable, cf)
+	}
+	opts := d.readOpts
+	return d.getCF(opts, handle, key)
 }
 
 // GetCodingShred returns the content of a given coding shred.
+//
+// Like GetDataShred, the caller has to Free the returned slice.
 func (d *DB) GetCodingShred(slot, index uint64) (*grocksdb.Slice, error) {
-	opts := grocksdb.NewDefaultReadOptions()
+	opts := d.readOpts
 	key := MakeShredKey(slot, index)
-	return d.db.GetCF(opts, d.cfCodeShred, key[:])
+	return d.getCF(opts, d.cfCodeShred, key[:])
+}
+
+// GetCodingShredBytes returns a copy of the content of a given coding shred.
+//
+// Returns ErrNotFound if the shred does not exist.
+func (d *DB) GetCodingShredBytes(slot, index uint64) ([]byte, error) {
+	key := MakeShredKey(slot, index)
+	return d.getBytes(d.cfCodeShred, key[:])
+}
+
+// GetMaxDataShredIndex returns the highest index of the data shreds of a slot.
+//
+// Returns false if the slot has no data shreds.
+func (d *DB) GetMaxDataShredIndex(slot uint64) (uint64, bool, error) {
+	return d.getMaxShredIndex(slot, d.cfDataShred)
+}
+
+// GetMaxCodingShredIndex returns the highest index of the coding shreds of a slot.
+//
+// Returns false if the slot has no coding shreds.
+func (d *DB) GetMaxCodingShredIndex(slot uint64) (uint64, bool, error) {
+	return d.getMaxShredIndex(slot, d.cfCodeShred)
+}
+
+func (d *DB) getMaxShredIndex(slot uint64, cf *grocksdb.ColumnFamilyHandle) (uint64, bool, error) {
+	iter := IterShred{Iterator: d.iterShreds(d.readOpts, cf)}
+	defer iter.Close()
+	key := MakeShredKey(slot, math.MaxUint64)
+	iter.SeekForPrev(key[:])
+	if err := iter.Err(); err != nil {
+		return 0, false, err
+	}
+	if !iter.Valid() {
+		return 0, false, nil
+	}
+	keySlot, index, err := iter.KeyErr()
+	if err != nil {
+		return 0, false, err
+	}
+	if keySlot == slot {
+		return index, true, nil
+	}
+	return 0, false, nil
+}
+
+// getBytes returns a copy of a value, freeing the RocksDB slice.
+func (d *DB) getBytes(cf *grocksdb.ColumnFamilyHandle, key []byte) ([]byte, error) {
+	res, err := d.getCF(d.readOpts, cf, key)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Free()
+	if !res.Exists() {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), res.Data()...), nil
+}
+
+// getCF reads a value, charging the rate limiter for its size.
+// It's the caller's responsibility to free the returned slice.
+func (d *DB) getCF(opts *grocksdb.ReadOptions, cf *grocksdb.ColumnFamilyHandle, key []byte) (*grocksdb.Slice, error) {
+	res, err := d.db.GetCF(opts, cf, key)
+	if err != nil {
+		return nil, err
+	}
+	d.limiter.charge(res.Size())
+	return res, nil
 }
 
 // IterDataShreds creates an iterator over CfDataShred.
 //
 // Use MakeSlotKey to construct a prefix,
 // or MakeShredKey to seek to a specific shred.
+// Nil options select the defaults.
 //
 // It's the caller's responsibility to close the iterator.
-func (d *DB) IterDataShreds(opts *grocksdb.ReadOptions) *grocksdb.Iterator {
-	return d.iterShreds(opts, d.cfDataShred)
+func (d *DB) IterDataShreds(o *IterOptions) IterShred {
+	return d.newIterShred(o, d.cfDataShred)
 }
 
 // IterCodingShreds creates an iterator over CfCodeShred.
 //
 // Use MakeSlotKey to construct a prefix,
 // or MakeShredKey to seek to a specific shred.
+// Nil options select the defaults.
+//
+// It's the caller's responsibility to close the iterator.
+func (d *DB) IterCodingShreds(o *IterOptions) IterShred {
+	return d.newIterShred(o, d.cfCodeShred)
+}
+
+func (d *DB) newIterShred(o *IterOptions, cf *grocksdb.ColumnFamilyHandle) IterShred {
+	opts := d.newUserIterReadOptions(o)
+	iter := IterShred{Iterator: d.iterShreds(opts, cf), limiter: d.limiter}
+	if o != nil {
+		iter.opts = opts
+	}
+	return iter
+}
+
+// IterDataShredsForSlot creates an iterator over the data shreds of a slot
+// in ascending index order, positioned at the first shred.
 //
 // It's the caller's responsibility to close the iterator.
-func (d *DB) IterCodingShreds(opts *grocksdb.ReadOptions) *grocksdb.Iterator {
-	return d.iterShreds(opts, d.cfCodeShred)
+func (d *DB) IterDataShredsForSlot(slot uint64) *ShredIterator {
+	return d.iterShredsForSlot(slot, d.cfDataShred)
+}
+
+// IterCodingShredsForSlot creates an iterator over the coding shreds of a slot
+// in ascending index order, positioned at the first shred.
+//
+// It's the caller's responsibility to close the iterator.
+func (d *DB) IterCodingShredsForSlot(slot uint64) *ShredIterator {
+	return d.iterShredsForSlot(slot, d.cfCodeShred)
+}
+
+// GetShredVersion returns the shred version of a slot,
+// as found in the common header of its first data shred.
+//
+// All shreds of a slot are expected to have the same version,
+// which only changes at hard forks.
+// Returns ErrNotFound if the slot has no data shreds.
+func (d *DB) GetShredVersion(slot uint64) (uint16, error) {
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	if !iter.Valid() {
+		if err := iter.Err(); err != nil {
+			return 0, err
+		}
+		return 0, ErrNotFound
+	}
+	value := iter.Value().Data()
+	d.limiter.charge(len(value))
+	var header shred.CommonHeader
+	if err := bin.NewBinDecoder(value).Decode(&header); err != nil {
+		return 0, fmt.Errorf("invalid data shred in slot %d: %w", slot, err)
+	}
+	return header.Version, nil
+}
+
+// GetDataShredsForSlot returns the parsed data shreds of a slot, ordered by index.
+//
+// Returns ErrInvalidShredData if any shred is missing
+// between index 0 and the highest received shred.
+func (d *DB) GetDataShredsForSlot(slot uint64) ([]shred.Shred, error) {
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	var shreds []shred.Shred
+	for ; iter.Valid(); iter.Next() {
+		index, s, err := iter.Shred()
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize shred %d/%d: %w", slot, index, err)
+		}
+		if index != uint64(len(shreds)) {
+			return nil, fmt.Errorf("%w: missing shred for slot %d, index %d", ErrInvalidShredData, slot, len(shreds))
+		}
+		shreds = append(shreds, s)
+	}
+	return shreds, iter.Err()
+}
+
+func (d *DB) iterShredsForSlot(slot uint64, cf *grocksdb.ColumnFamilyHandle) *ShredIterator {
+	lower, upper := MakeShredKeyRange(slot)
+	opts := d.newIterReadOptions()
+	opts.SetIterateUpperBound(upper[:])
+	iter := &ShredIterator{IterShred{Iterator: d.db.NewIteratorCF(opts, cf), opts: opts, limiter: d.limiter}}
+	iter.Seek(lower[:])
+	return iter
 }
 
 func (d *DB) iterShreds(opts *grocksdb.ReadOptions, cf *grocksdb.ColumnFamilyHandle) *grocksdb.Iterator {
 	if opts == nil {
-		opts = grocksdb.NewDefaultReadOptions()
+		opts = d.iterOpts
 	}
 	return d.db.NewIteratorCF(opts, cf)
 }
 
 func (d *DB) GetBlock(slot uint64) (*Block, error) {
-	// TODO Retrieving slot meta twice, which sucks
+	return d.GetBlockContext(context.Background(), slot)
+}
+
+// GetBlockContext is like GetBlock, tracing the call with the DB's Tracer.
+func (d *DB) GetBlockContext(ctx context.Context, slot uint64) (*Block, error) {
+	block, _, err := d.GetBlockWithOptions(ctx, slot, GetBlockOptions{})
+	return block, err
+}
+
+// GetBlockOptions controls how GetBlockWithOptions assembles a block.
+type GetBlockOptions struct {
+	// SkipUndecodable skips data blocks that cannot be deshredded or decoded
+	// instead of failing, keeping the entries decoded before the error.
+	SkipUndecodable bool
+}
+
+// UndecodableDataBlock is a data block skipped by GetBlockWithOptions.
+type UndecodableDataBlock struct {
+	StartIndex uint32
+	EndIndex   uint32
+	Err        error
+}
+
+// GetBlockWithOptions is like GetBlockContext,
+// additionally returning the data blocks skipped with GetBlockOptions.SkipUndecodable.
+//
+// If the last data block of the slot is skipped, the block hash is left zero.
+func (d *DB) GetBlockWithOptions(ctx context.Context, slot uint64, opts GetBlockOptions) (_ *Block, skipped []UndecodableDataBlock, err error) {
+	ctx, span := d.startSpan(ctx, "GetBlock", slot)
+	defer func() { span.End(err) }()
+
 	meta, err := d.GetSlotMeta(slot)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if !meta.IsFull() {
-		return nil, ErrNotFound
+		return nil, nil, ErrNotFound
 	}
-	entries, _, _, err := d.GetSlotEntries(slot, 0, false)
-	if err != nil {
-		return nil, err
+	var entries []Entry
+	lastDecoded := false
+	for _, completed := range completedRangesOfMeta(meta, 0) {
+		subEntries, err := d.getEntriesInDataBlock(ctx, slot, completed.StartIndex, completed.EndIndex, blockReadOptions{})
+		entries = append(entries, subEntries...)
+		lastDecoded = err == nil
+		if err == nil {
+			continue
+		}
+		if !opts.SkipUndecodable {
+			return nil, nil, err
+		}
+		skipped = append(skipped, UndecodableDataBlock{
+			StartIndex: completed.StartIndex,
+			EndIndex:   completed.EndIndex,
+			Err:        err,
+		})
 	}
-	if len(entries) == 0 {
-		return nil, ErrNotFound
+	if len(entries) == 0 && len(skipped) == 0 {
+		return nil, nil, ErrNotFound
+	}
+	var blockHash solana.Hash
+	if lastDecoded && len(entries) > 0 {
+		blockHash = entries[len(entries)-1].Hash
 	}
-	blockHash := entries[len(entries)-1].Hash
-	var txns []solana.Transaction
+	var txns []Transaction
 	for _, entry := range entries {
 		txns = append(txns, entry.Transactions...)
 	}
@@ -294,23 +1039,153 @@ func (d *DB) GetBlock(slot uint64) (*Block, error) {
 		ParentSlot:   meta.ParentSlot,
 		Transactions: txns,
 	}
-	return block, nil
+	if parent := meta.ParentSlotOpt(); parent != nil && *parent != slot {
+		// Like the validator, leave the hash zero if the parent was purged.
+		block.PreviousBlockHash, err = d.getLastEntryHash(*parent)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, nil, fmt.Errorf("cannot get blockhash of parent slot %d: %w", *parent, err)
+		}
+	}
+	return block, skipped, nil
+}
+
+// multiGetBlocksWorkers is the maximum number of blocks decoded concurrently by MultiGetBlocks.
+const multiGetBlocksWorkers = 8
+
+// MultiGetBlocks fetches multiple blocks concurrently.
+//
+// The results and errors are in the order of slots.
+// For each slot, exactly one of the block and the error is nil.
+func (d *DB) MultiGetBlocks(slots ...uint64) ([]*Block, []error) {
+	blocks := make([]*Block, len(slots))
+	errs := make([]error, len(slots))
+
+	workers := multiGetBlocksWorkers
+	if len(slots) < workers {
+		workers = len(slots)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				blocks[i], errs[i] = d.GetBlock(slots[i])
+			}
+		}()
+	}
+	for i := range slots {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return blocks, errs
 }
 
 // GetSlotEntries returns the entry vector for the slot starting
 // with `shred_start_index`, the number of shreds that comprise the entry
 // vector, and whether the slot is full (consumed all shreds).
 //
+// Reading can be resumed later at startIndex+numShreds.
+//
 // See https://docs.rs/solana-ledger/latest/solana_ledger/blockstore/struct.Blockstore.html#method.get_slot_entries_with_shred_info
 func (d *DB) GetSlotEntries(
 	slot uint64,
 	startIndex uint64,
 	allowDeadSlots bool,
 ) (entries []Entry, numShreds uint64, isFull bool, err error) {
-	completedRanges, slotMeta, err := d.getCompletedRanges(slot, startIndex)
-	if err != nil {
+	return d.GetSlotEntriesContext(context.Background(), slot, startIndex, allowDeadSlots)
+}
+
+// GetSlotEntriesWithVersion is like GetSlotEntries,
+// but fails with ErrShredVersionMismatch if any shred has a version other than shredVersion.
+//
+// The shred version identifies the cluster and changes at every hard fork.
+// See GetShredVersion for determining the version of a slot.
+func (d *DB) GetSlotEntriesWithVersion(
+	slot uint64,
+	startIndex uint64,
+	allowDeadSlots bool,
+	shredVersion uint16,
+) (entries []Entry, numShreds uint64, isFull bool, err error) {
+	return d.getSlotEntries(context.Background(), slot, startIndex, allowDeadSlots, blockReadOptions{shredVersion: shredVersion})
+}
+
+// GetSlotEntriesContext is like GetSlotEntries, tracing the call with the DB's Tracer.
+func (d *DB) GetSlotEntriesContext(
+	ctx context.Context,
+	slot uint64,
+	startIndex uint64,
+	allowDeadSlots bool,
+) (entries []Entry, numShreds uint64, isFull bool, err error) {
+	return d.getSlotEntries(ctx, slot, startIndex, allowDeadSlots, blockReadOptions{})
+}
+
+func (d *DB) getSlotEntries(
+	ctx context.Context,
+	slot uint64,
+	startIndex uint64,
+	allowDeadSlots bool,
+	o blockReadOptions,
+) (entries []Entry, numShreds uint64, isFull bool, err error) {
+	ctx, span := d.startSpan(ctx, "GetSlotEntries", slot)
+	defer func() { span.End(err) }()
+
+	// The validator locks here to prevent purges.
+	// We're not in the validator's memory space, so we cannot acquire a lock here.
+	meta, err := d.GetSlotMeta(slot)
+	if errors.Is(err, ErrNotFound) {
+		return nil, 0, false, nil
+	} else if err != nil {
+		return nil, 0, false, err
+	}
+	return d.getSlotEntriesWithMeta(ctx, meta, startIndex, allowDeadSlots, o)
+}
+
+// GetSlotEntriesWithShredInfo is like GetSlotEntries,
+// but returns the entries grouped by the completed range of data shreds they were decoded from.
+//
+// See https://docs.rs/solana-ledger/latest/solana_ledger/blockstore/struct.Blockstore.html#method.get_slot_entries_with_shred_info
+func (d *DB) GetSlotEntriesWithShredInfo(
+	slot uint64,
+	startIndex uint64,
+	allowDeadSlots bool,
+) (blocks []DataBlockEntries, numShreds uint64, isFull bool, err error) {
+	meta, err := d.GetSlotMeta(slot)
+	if errors.Is(err, ErrNotFound) {
+		return nil, 0, false, nil
+	} else if err != nil {
 		return nil, 0, false, err
 	}
+	return d.getSlotDataBlocksWithMeta(context.Background(), meta, startIndex, allowDeadSlots, blockReadOptions{})
+}
+
+// getSlotEntriesWithMeta is GetSlotEntries with an already retrieved slot meta.
+func (d *DB) getSlotEntriesWithMeta(
+	ctx context.Context,
+	meta *SlotMeta,
+	startIndex uint64,
+	allowDeadSlots bool,
+	o blockReadOptions,
+) (entries []Entry, numShreds uint64, isFull bool, err error) {
+	blocks, numShreds, isFull, err := d.getSlotDataBlocksWithMeta(ctx, meta, startIndex, allowDeadSlots, o)
+	for _, block := range blocks {
+		entries = append(entries, block.Entries...)
+	}
+	return entries, numShreds, isFull, err
+}
+
+// getSlotDataBlocksWithMeta is GetSlotEntriesWithShredInfo with an already retrieved slot meta.
+func (d *DB) getSlotDataBlocksWithMeta(
+	ctx context.Context,
+	meta *SlotMeta,
+	startIndex uint64,
+	allowDeadSlots bool,
+	o blockReadOptions,
+) (blocks []DataBlockEntries, numShreds uint64, isFull bool, err error) {
+	slot := meta.Slot
+	completedRanges := completedRangesOfMeta(meta, startIndex)
 
 	if allowDeadSlots {
 		isDead, err := d.IsSlotDead(slot)
@@ -328,18 +1203,100 @@ func (d *DB) GetSlotEntries(
 
 	// TODO parallel
 	for _, completed := range completedRanges {
-		subEntries, err := d.GetEntriesInDataBlock(slot, completed.StartIndex, completed.EndIndex)
+		subEntries, err := d.getEntriesInDataBlock(ctx, slot, completed.StartIndex, completed.EndIndex, o)
 		if err != nil {
-			return entries, numShreds, false, err
+			return blocks, numShreds, false, err
 		}
-		entries = append(entries, subEntries...)
+		blocks = append(blocks, DataBlockEntries{Range: completed, Entries: subEntries})
 	}
 
-	isFull = slotMeta.IsFull()
+	isFull = meta.IsFull()
 	return
 }
 
-func (d *DB) getCompletedRanges(slot uint64, startIndex uint64) ([]CompletedRange, *SlotMeta, error) {
+// GetSlotEntriesComplete returns all entries of a full slot,
+// reading its data shreds in a single pass.
+//
+// Returns ErrNotFound if the slot is not full,
+// and ErrInvalidShredData if any of its data shreds are missing.
+func (d *DB) GetSlotEntriesComplete(slot uint64) ([]Entry, error) {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsFull() {
+		return nil, ErrNotFound
+	}
+
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	var entries []Entry
+	for _, completed := range completedRangesOfMeta(meta, 0) {
+		payload, err := d.readDataBlockPayload(context.Background(), iter, slot, completed.StartIndex, completed.EndIndex, blockReadOptions{})
+		if err != nil {
+			return nil, err
+		}
+		subEntries, err := decodeEntries(payload)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode entries of slot %d: %w", slot, err)
+		}
+		entries = append(entries, subEntries...)
+	}
+	return entries, nil
+}
+
+// StreamEntries decodes the entries of the completed data blocks of a slot one by one,
+// invoking fn for each entry in order.
+//
+// Unlike GetSlotEntries, at most one data block payload and one entry are held in memory.
+// Stops and returns the error if fn returns an error.
+// Returns ErrNotFound if the slot has no meta.
+func (d *DB) StreamEntries(slot uint64, fn func(Entry) error) error {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return err
+	}
+
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	for _, completed := range completedRangesOfMeta(meta, 0) {
+		payload, err := d.readDataBlockPayload(context.Background(), iter, slot, completed.StartIndex, completed.EndIndex, blockReadOptions{})
+		if err != nil {
+			return err
+		}
+		if err := streamEntries(payload, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNextDataBlock returns the entries of the first completed data block
+// starting at `startIndex`, and the shred index at which the next data block starts.
+//
+// Returns ErrNotFound if no completed data block is available yet.
+func (d *DB) GetNextDataBlock(slot uint64, startIndex uint64) (entries []Entry, nextIndex uint64, err error) {
+	completedRanges, _, err := d.GetCompletedRanges(slot, startIndex)
+	if err != nil {
+		return nil, startIndex, err
+	}
+	if len(completedRanges) == 0 {
+		return nil, startIndex, ErrNotFound
+	}
+	first := completedRanges[0]
+	entries, err = d.GetEntriesInDataBlock(slot, first.StartIndex, first.EndIndex)
+	if err != nil {
+		return nil, startIndex, err
+	}
+	return entries, uint64(first.EndIndex) + 1, nil
+}
+
+// GetCompletedRanges returns the index ranges [StartIndex, EndIndex] of the completed data blocks
+// of a slot starting at `startIndex`, along with the slot meta.
+//
+// Each range can be decoded using GetEntriesInDataBlock.
+// Returns no ranges and a nil meta if the slot has no meta.
+func (d *DB) GetCompletedRanges(slot uint64, startIndex uint64) ([]CompletedRange, *SlotMeta, error) {
 	// The validator locks here to prevent purges.
 	// We're not in the validator's memory space, so we cannot acquire a lock here.
 	meta, err := d.GetSlotMeta(slot)
@@ -348,13 +1305,20 @@ func (d *DB) getCompletedRanges(slot uint64, startIndex uint64) ([]CompletedRang
 	} else if err != nil {
 		return nil, nil, err
 	}
-	// Find all the ranges for the completed data blocks
-	completedRanges := getCompletedDataRanges(uint32(startIndex), meta.CompletedDataIndexes, uint32(meta.Consumed))
-	return completedRanges, meta, nil
+	return completedRangesOfMeta(meta, startIndex), meta, nil
+}
+
+// completedRangesOfMeta finds all the ranges for the completed data blocks.
+func completedRangesOfMeta(meta *SlotMeta, startIndex uint64) []CompletedRange {
+	return ComputeCompletedRanges(uint32(startIndex), meta.CompletedDataIndexes, uint32(meta.Consumed))
 }
 
-// Get the range of indexes [start_index, end_index] of every completed data block
-func getCompletedDataRanges(
+// ComputeCompletedRanges returns the range of indexes [StartIndex, EndIndex] of every completed data block
+// given the CompletedDataIndexes and Consumed fields of a slot meta.
+//
+// Only data blocks ending in [startIndex, consumed) are returned.
+// The first range starts at startIndex, each following range starts after the end of the previous one.
+func ComputeCompletedRanges(
 	startIndex uint32,
 	completedDataIndexes []uint32,
 	consumed uint32,
@@ -364,45 +1328,243 @@ func getCompletedDataRanges(
 	begin := startIndex
 	for _, index := range completedDataIndexes {
 		ranges = append(ranges, CompletedRange{begin, index})
-		begin++
+		begin = index + 1
 	}
 	return ranges
 }
 
+// GetCompletedDataSets returns the completed data sets of a slot,
+// i.e. the ranges of data shreds that each deshred to an entry vector.
+//
+// Matches Blockstore::get_completed_data_set_infos of the validator
+// except that only data sets up to the first missing shred are returned.
+func (d *DB) GetCompletedDataSets(slot uint64) ([]CompletedDataSetInfo, error) {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return nil, err
+	}
+	ranges := completedRangesOfMeta(meta, 0)
+	sets := make([]CompletedDataSetInfo, len(ranges))
+	for i, r := range ranges {
+		sets[i] = CompletedDataSetInfo{
+			Slot:       slot,
+			StartIndex: r.StartIndex,
+			EndIndex:   r.EndIndex,
+			LastInSlot: meta.LastIndex == uint64(r.EndIndex),
+		}
+	}
+	return sets, nil
+}
+
+// GetSlotPayload returns the deshredded data of all completed data blocks
+// in the slot starting with `startIndex`, without decoding entries.
+//
+// The payload is a concatenation of serialized entry vectors, one per data block.
+func (d *DB) GetSlotPayload(slot uint64, startIndex uint64) ([]byte, error) {
+	completedRanges, _, err := d.GetCompletedRanges(slot, startIndex)
+	if err != nil {
+		return nil, err
+	}
+	var payload []byte
+	for _, completed := range completedRanges {
+		blockPayload, err := d.getDataBlockPayload(context.Background(), slot, completed.StartIndex, completed.EndIndex, blockReadOptions{})
+		if err != nil {
+			return nil, err
+		}
+		payload = append(payload, blockPayload...)
+	}
+	return payload, nil
+}
+
 func (d *DB) GetEntriesInDataBlock(slot uint64, startIndex uint32, endIndex uint32) ([]Entry, error) {
-	iter := d.db.NewIteratorCF(grocksdb.NewDefaultReadOptions(), d.cfDataShred)
+	return d.getEntriesInDataBlock(context.Background(), slot, startIndex, endIndex, blockReadOptions{})
+}
+
+// GetEntriesInDataBlockWithRecovery is like GetEntriesInDataBlock,
+// but attempts to recover missing data shreds from the coding shreds
+// of their erasure set, both for legacy and Merkle shreds.
+//
+// Requires CfErasureMeta. See shred.Recover for the fields of recovered Merkle shreds.
+func (d *DB) GetEntriesInDataBlockWithRecovery(slot uint64, startIndex uint32, endIndex uint32) ([]Entry, error) {
+	return d.getEntriesInDataBlock(context.Background(), slot, startIndex, endIndex, blockReadOptions{allowRecovery: true})
+}
+
+func (d *DB) getEntriesInDataBlock(ctx context.Context, slot uint64, startIndex uint32, endIndex uint32, o blockReadOptions) ([]Entry, error) {
+	payload, err := d.getDataBlockPayload(ctx, slot, startIndex, endIndex, o)
+	if err != nil {
+		return nil, err
+	}
+	return decodeEntries(payload)
+}
+
+// CountEntries returns the number of entries and transactions
+// in the completed data blocks of a slot.
+//
+// Transactions are skipped over rather than decoded,
+// making this cheaper than GetBlock for gathering statistics.
+// Returns ErrNotFound if the slot has no meta.
+func (d *DB) CountEntries(slot uint64) (numEntries uint64, numTxns uint64, err error) {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	for _, completed := range completedRangesOfMeta(meta, 0) {
+		payload, err := d.readDataBlockPayload(context.Background(), iter, slot, completed.StartIndex, completed.EndIndex, blockReadOptions{})
+		if err != nil {
+			return 0, 0, err
+		}
+		entries, txns, err := countEntries(payload)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot count entries of slot %d: %w", slot, err)
+		}
+		numEntries += entries
+		numTxns += txns
+	}
+	return numEntries, numTxns, nil
+}
+
+// countEntries counts the entries and transactions of a data block payload.
+func countEntries(payload []byte) (numEntries uint64, numTxns uint64, err error) {
+	dec := bin.NewBinDecoder(payload)
+	count, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return 0, 0, err
+	}
+	if count > uint64(len(payload)) {
+		return 0, 0, fmt.Errorf("invalid entry count %d", count)
+	}
+	for i := uint64(0); i < count; i++ {
+		// NumHashes and Hash
+		if err := dec.SkipBytes(8 + 32); err != nil {
+			return 0, 0, fmt.Errorf("cannot decode entry %d: %w", i, err)
+		}
+		n, err := dec.ReadUint64(bin.LE)
+		if err != nil {
+			return 0, 0, fmt.Errorf("cannot decode entry %d: %w", i, err)
+		}
+		if n > uint64(dec.Remaining()) {
+			return 0, 0, fmt.Errorf("invalid transaction count %d in entry %d", n, i)
+		}
+		for j := uint64(0); j < n; j++ {
+			if err := skipTransaction(dec); err != nil {
+				return 0, 0, fmt.Errorf("cannot decode transaction %d of entry %d: %w", j, i, err)
+			}
+		}
+		numTxns += n
+	}
+	return count, numTxns, nil
+}
+
+// decodeEntries decodes the entries of a data block payload.
+//
+// On error, returns the entries decoded so far.
+func decodeEntries(payload []byte) ([]Entry, error) {
+	var entries []Entry
+	err := streamEntries(payload, func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+// streamEntries decodes the entries of a data block payload one by one,
+// invoking fn for each entry.
+//
+// Errors returned by fn are passed through unchanged.
+func streamEntries(payload []byte, fn func(Entry) error) error {
+	dec := bin.NewBinDecoder(payload)
+	count, err := dec.ReadUint64(bin.LE)
+	if err != nil {
+		return err
+	}
+	if count > uint64(len(payload)) {
+		return fmt.Errorf("invalid entry count %d", count)
+	}
+	for i := uint64(0); i < count; i++ {
+		var entry Entry
+		if err := dec.Decode(&entry); err != nil {
+			return fmt.Errorf("cannot decode entry %d: %w", i, err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDataBlockPayload deshreds the data shreds in the index range [startIndex, endIndex].
+func (d *DB) getDataBlockPayload(ctx context.Context, slot uint64, startIndex uint32, endIndex uint32, o blockReadOptions) ([]byte, error) {
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	return d.readDataBlockPayload(ctx, iter, slot, startIndex, endIndex, o)
+}
+
+// readDataBlockPayload is like getDataBlockPayload but reuses an iterator over the slot's data shreds.
+func (d *DB) readDataBlockPayload(ctx context.Context, iter *ShredIterator, slot uint64, startIndex uint32, endIndex uint32, o blockReadOptions) ([]byte, error) {
 	key := MakeShredKey(slot, uint64(startIndex))
 	iter.Seek(key[:])
 	var shreds []shred.Shred
+	var missing []uint64
+	recovered := make(map[uint64]shred.Shred)
 	for i := uint64(startIndex); i <= uint64(endIndex); i++ {
-		var slot, index uint64
-		valid := iter.Valid()
-		if valid {
-			slot = binary.BigEndian.Uint64(iter.Key().Data())
-			index = binary.BigEndian.Uint64(iter.Key().Data()[8:])
+		if iter.Valid() {
+			_, index, err := iter.KeyErr()
+			if err != nil {
+				return nil, err
+			}
+			if index == i {
+				s, err := iter.Element()
+				if err != nil {
+					return nil, fmt.Errorf("failed to deserialize shred %d/%d: %w", slot, i, err)
+				}
+				shreds = append(shreds, s)
+				iter.Next()
+				continue
+			}
+		}
+		if s, ok := recovered[i]; ok {
+			shreds = append(shreds, s)
+			continue
+		}
+		if !o.allowRecovery {
+			missing = append(missing, i)
+			continue
+		}
+		set, err := d.recoverErasureSet(ctx, slot, i)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot recover shred %d/%d: %v", ErrInvalidShredData, slot, i, err)
 		}
-		if !valid || index != i {
-			return nil, fmt.Errorf("%w: missing shred for slot %d, index %d", ErrInvalidShredData, slot, index)
+		for _, s := range set {
+			recovered[uint64(s.CommonHeader().Index)] = s
 		}
-		s := shred.NewShredFromSerialized(iter.Value().Data())
-		if s == nil {
-			return nil, fmt.Errorf("failed to deserialize shred %d/%d", slot, i)
+		s, ok := recovered[i]
+		if !ok {
+			return nil, fmt.Errorf("%w: shred %d/%d not recovered", ErrInvalidShredData, slot, i)
 		}
 		shreds = append(shreds, s)
 	}
-
-	payload, err := shred.Deshred(shreds)
-	if err != nil {
-		return nil, err
+	if len(missing) > 0 {
+		return nil, &ErrMissingShreds{Slot: slot, Missing: missing}
 	}
 
-	var entries struct {
-		Count   uint64 `bin:"sizeof=Entries"`
-		Entries []Entry
+	if o.shredVersion != 0 {
+		for _, s := range shreds {
+			if v := s.CommonHeader().Version; v != o.shredVersion {
+				return nil, fmt.Errorf("%w: shred %d/%d has version %d, expected %d",
+					ErrShredVersionMismatch, slot, s.CommonHeader().Index, v, o.shredVersion)
+			}
+		}
 	}
-	dec := bin.NewBinDecoder(payload)
-	err = dec.Decode(&entries)
-	return entries.Entries, err
+	return shred.Deshred(shreds)
+}
+
+// blockReadOptions control how data blocks are read from shreds.
+type blockReadOptions struct {
+	allowRecovery bool   // recover missing data shreds from coding shreds
+	shredVersion  uint16 // if nonzero, reject shreds of other versions
 }
 
 func sliceSortedByRange[T constraints.Ordered](list []T, start T, stop T) []T {
@@ -0,0 +1,80 @@
+package blockstore
+
+import (
+	"container/list"
+	"sync"
+)
+
+// slotMetaCache is a bounded LRU cache of slot metas.
+type slotMetaCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // front is most recently used
+	items map[uint64]*list.Element
+}
+
+func newSlotMetaCache(size int) *slotMetaCache {
+	return &slotMetaCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[uint64]*list.Element, size),
+	}
+}
+
+// get returns a copy of the cached slot meta, if any.
+func (c *slotMetaCache) get(slot uint64) (*SlotMeta, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[slot]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return copySlotMeta(elem.Value.(*SlotMeta)), true
+}
+
+func (c *slotMetaCache) put(meta *SlotMeta) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached := copySlotMeta(meta)
+	if elem, ok := c.items[meta.Slot]; ok {
+		elem.Value = cached
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[meta.Slot] = c.order.PushFront(cached)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*SlotMeta).Slot)
+	}
+}
+
+func (c *slotMetaCache) purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.items = make(map[uint64]*list.Element, c.size)
+}
+
+// copySlotMeta returns a deep copy of meta,
+// so that callers cannot modify the slices of cached metas.
+func copySlotMeta(meta *SlotMeta) *SlotMeta {
+	c := *meta
+	if meta.NextSlots != nil {
+		c.NextSlots = append([]uint64{}, meta.NextSlots...)
+	}
+	if meta.CompletedDataIndexes != nil {
+		c.CompletedDataIndexes = append([]uint32{}, meta.CompletedDataIndexes...)
+	}
+	return &c
+}
@@ -0,0 +1,25 @@
+package blockstore
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlotMetaCacheCopies(t *testing.T) {
+	c := newSlotMetaCache(1)
+	meta := &SlotMeta{Slot: 1, NextSlots: []uint64{2, 3}, CompletedDataIndexes: []uint32{4, 5}}
+	c.put(meta)
+	meta.NextSlots[0] = 0
+
+	got, ok := c.get(1)
+	if !ok {
+		t.Fatal("slot meta not cached")
+	}
+	got.NextSlots[1] = 0
+	got.CompletedDataIndexes[0] = 0
+
+	want := &SlotMeta{Slot: 1, NextSlots: []uint64{2, 3}, CompletedDataIndexes: []uint32{4, 5}}
+	if again, _ := c.get(1); !reflect.DeepEqual(again, want) {
+		t.Errorf("cached %+v, want %+v", again, want)
+	}
+}
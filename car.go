@@ -0,0 +1,167 @@
+package blockstore
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+// Blocks are exported to CAR v1 files (https://ipld.io/specs/transport/car/carv1/)
+// as a DAG with one root per slot.
+//
+// The root node is DAG-CBOR (codec 0x71) with the following map, keys in canonical order:
+//
+//	{
+//	  "txs":       [&tx, ...]  // links to the transactions, in block order
+//	  "hash":      bytes       // blockhash (32 bytes)
+//	  "slot":      int
+//	  "parent":    int         // parent slot
+//	  "prev_hash": bytes       // blockhash of the parent slot (32 bytes)
+//	}
+//
+// Each transaction is a raw node (codec 0x55) holding the transaction in wire format.
+// All CIDs are CIDv1 with a SHA2-256 multihash.
+// The CAR file contains the root node first, followed by the transactions.
+
+const (
+	codecRaw     = 0x55
+	codecDagCBOR = 0x71
+	mhSHA2_256   = 0x12
+)
+
+// CID is a CIDv1 with a SHA2-256 multihash.
+type CID [36]byte
+
+func newCID(codec byte, data []byte) (c CID) {
+	c[0] = 0x01 // CIDv1
+	c[1] = codec
+	c[2] = mhSHA2_256
+	c[3] = sha256.Size
+	digest := sha256.Sum256(data)
+	copy(c[4:], digest[:])
+	return
+}
+
+var multibaseBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// String returns the multibase base32 representation of the CID, as used by IPFS.
+func (c CID) String() string {
+	return "b" + strings.ToLower(multibaseBase32.EncodeToString(c[:]))
+}
+
+// WriteBlockCAR writes a block as a CAR file and returns the CID of its root node.
+func WriteBlockCAR(w io.Writer, slot uint64, block *Block) (CID, error) {
+	txs := make([][]byte, len(block.Transactions))
+	txCIDs := make([]CID, len(block.Transactions))
+	for i := range block.Transactions {
+		raw, err := block.Transactions[i].MarshalBinary()
+		if err != nil {
+			return CID{}, err
+		}
+		txs[i] = raw
+		txCIDs[i] = newCID(codecRaw, raw)
+	}
+
+	var root []byte
+	root = appendCBORHeader(root, cborMap, 5)
+	root = appendCBORString(root, "txs")
+	root = appendCBORHeader(root, cborArray, uint64(len(txCIDs)))
+	for _, c := range txCIDs {
+		root = appendCBORLink(root, c)
+	}
+	root = appendCBORString(root, "hash")
+	root = appendCBORBytes(root, block.BlockHash[:])
+	root = appendCBORString(root, "slot")
+	root = appendCBORHeader(root, cborUint, slot)
+	root = appendCBORString(root, "parent")
+	root = appendCBORHeader(root, cborUint, block.ParentSlot)
+	root = appendCBORString(root, "prev_hash")
+	root = appendCBORBytes(root, block.PreviousBlockHash[:])
+	rootCID := newCID(codecDagCBOR, root)
+
+	var header []byte
+	header = appendCBORHeader(header, cborMap, 2)
+	header = appendCBORString(header, "roots")
+	header = appendCBORHeader(header, cborArray, 1)
+	header = appendCBORLink(header, rootCID)
+	header = appendCBORString(header, "version")
+	header = appendCBORHeader(header, cborUint, 1)
+
+	if err := writeCARSection(w, header); err != nil {
+		return CID{}, err
+	}
+	if err := writeCARSection(w, rootCID[:], root); err != nil {
+		return CID{}, err
+	}
+	for i, tx := range txs {
+		if err := writeCARSection(w, txCIDs[i][:], tx); err != nil {
+			return CID{}, err
+		}
+	}
+	return rootCID, nil
+}
+
+// writeCARSection writes the concatenation of parts prefixed with its varint length.
+func writeCARSection(w io.Writer, parts ...[]byte) error {
+	var size int
+	for _, part := range parts {
+		size += len(part)
+	}
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+size)
+	buf = buf[:binary.PutUvarint(buf, uint64(size))]
+	for _, part := range parts {
+		buf = append(buf, part...)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// CBOR major types
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborText  = 3
+	cborArray = 4
+	cborMap   = 5
+	cborTag   = 6
+)
+
+// CBOR tag of IPLD links.
+const cborTagCID = 42
+
+func appendCBORHeader(b []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= 0xFF:
+		return append(b, major|24, byte(arg))
+	case arg <= 0xFFFF:
+		return append(b, major|25, byte(arg>>8), byte(arg))
+	case arg <= 0xFFFF_FFFF:
+		return append(b, major|26, byte(arg>>24), byte(arg>>16), byte(arg>>8), byte(arg))
+	default:
+		var v [8]byte
+		binary.BigEndian.PutUint64(v[:], arg)
+		return append(append(b, major|27), v[:]...)
+	}
+}
+
+func appendCBORBytes(b []byte, v []byte) []byte {
+	b = appendCBORHeader(b, cborBytes, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendCBORString(b []byte, v string) []byte {
+	b = appendCBORHeader(b, cborText, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendCBORLink(b []byte, c CID) []byte {
+	b = appendCBORHeader(b, cborTag, cborTagCID)
+	b = appendCBORHeader(b, cborBytes, uint64(len(c)+1))
+	b = append(b, 0x00) // multibase identity prefix
+	return append(b, c[:]...)
+}
@@ -0,0 +1,75 @@
+package blockstore_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+)
+
+// TestWriteBlockCARGolden pins the CAR encoding of a block
+// to bytes encoded independently following the CARv1 and DAG-CBOR specs.
+func TestWriteBlockCARGolden(t *testing.T) {
+	block := &blockstore.Block{
+		BlockHash:         solana.Hash{1},
+		PreviousBlockHash: solana.Hash{2},
+		ParentSlot:        41,
+		Transactions:      []blockstore.Transaction{testTransaction(1)},
+	}
+	var buf bytes.Buffer
+	root, err := blockstore.WriteBlockCAR(&buf, 42, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const wantRoot = "bafyreibzzrx5gqwjwpf7kyepwgwpi6wzzfagktd2puqibt4gltnn56wbji"
+	if got := root.String(); got != wantRoot {
+		t.Errorf("root CID %s, want %s", got, wantRoot)
+	}
+
+	sections := []struct{ name, hex string }{
+		// {"roots": [CID(root)], "version": 1}
+		{"header", "3a" +
+			"a2" + "65726f6f7473" + "81" + "d82a58250001711220" +
+			"39cc6fd342c9b3cbf5608fb1acf47ad9c940654c7a7d2080cf865cdadefac14a" +
+			"6776657273696f6e" + "01"},
+		// DAG-CBOR root node with keys in canonical order.
+		{"root", "b601" +
+			"0171122039cc6fd342c9b3cbf5608fb1acf47ad9c940654c7a7d2080cf865cdadefac14a" +
+			"a5" +
+			"63747873" + "81" + "d82a58250001551220" +
+			"c0d16698dd7e6d7e3b0f2b485e2b6b86dfd7820da635cbdaafd9a1f13a8e325f" +
+			"6468617368" + "5820" + "01" + strings.Repeat("00", 31) +
+			"64736c6f74" + "182a" +
+			"66706172656e74" + "1829" +
+			"69707265765f68617368" + "5820" + "02" + strings.Repeat("00", 31)},
+		// Raw transaction node.
+		{"transaction", "d001" +
+			"01551220c0d16698dd7e6d7e3b0f2b485e2b6b86dfd7820da635cbdaafd9a1f13a8e325f" +
+			"01" + "01" + strings.Repeat("00", 63) +
+			"010001" + "02" + "02" + strings.Repeat("00", 31) + "03" + strings.Repeat("00", 31) +
+			"04" + strings.Repeat("00", 31) +
+			"01" + "01" + "0100" + "020506"},
+	}
+	got := buf.Bytes()
+	for _, s := range sections {
+		want, err := hex.DecodeString(s.hex)
+		if err != nil {
+			t.Fatalf("%s: %s", s.name, err)
+		}
+		if !bytes.HasPrefix(got, want) {
+			n := len(want)
+			if n > len(got) {
+				n = len(got)
+			}
+			t.Fatalf("%s: got  %x\nwant %x", s.name, got[:n], want)
+		}
+		got = got[len(want):]
+	}
+	if len(got) != 0 {
+		t.Errorf("%d trailing bytes", len(got))
+	}
+}
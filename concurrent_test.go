@@ -0,0 +1,89 @@
+package blockstore_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+// TestConcurrentReads exercises the read options shared by all readers of a DB.
+// Run with -race.
+func TestConcurrentReads(t *testing.T) {
+	const numSlots = 8
+	b := testutil.NewLedger(t)
+	for slot := uint64(1); slot <= numSlots; slot++ {
+		b.AddBlock(slot, slot-1, []blockstore.Entry{{NumHashes: 1, Hash: solana.Hash{byte(slot)}}})
+		b.AddRoot(slot)
+	}
+	db, err := blockstore.OpenReadOnly(b.Path())
+	if err != nil {
+		t.Fatalf("cannot open ledger: %s", err)
+	}
+	defer db.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for slot := uint64(1); slot <= numSlots; slot++ {
+				meta, err := db.GetSlotMeta(slot)
+				if err != nil {
+					t.Errorf("GetSlotMeta(%d): %s", slot, err)
+					return
+				}
+				if meta.Slot != slot {
+					t.Errorf("GetSlotMeta(%d) returned slot %d", slot, meta.Slot)
+				}
+				block, err := db.GetBlock(slot)
+				if err != nil {
+					t.Errorf("GetBlock(%d): %s", slot, err)
+					return
+				}
+				if block.BlockHash != (solana.Hash{byte(slot)}) {
+					t.Errorf("GetBlock(%d) returned block hash %s", slot, block.BlockHash)
+				}
+
+				shreds := db.IterDataShredsForSlot(slot)
+				n := 0
+				for ; shreds.Valid(); shreds.Next() {
+					if _, _, err := shreds.Shred(); err != nil {
+						t.Errorf("slot %d: invalid shred: %s", slot, err)
+					}
+					n++
+				}
+				shreds.Close()
+				if n != 1 {
+					t.Errorf("slot %d: iterated %d data shreds", slot, n)
+				}
+			}
+
+			metas := db.IterSlotMetas(nil)
+			n := 0
+			for metas.SeekToFirst(); metas.Valid(); metas.Next() {
+				if _, _, err := metas.SlotMeta(); err != nil {
+					t.Errorf("invalid slot meta: %s", err)
+				}
+				n++
+			}
+			metas.Close()
+			if n != numSlots {
+				t.Errorf("iterated %d slot metas", n)
+			}
+
+			roots := db.IterRoots(0, numSlots)
+			n = 0
+			for ; roots.Valid(); roots.Next() {
+				n++
+			}
+			roots.Close()
+			if n != numSlots {
+				t.Errorf("iterated %d roots", n)
+			}
+		}()
+	}
+	wg.Wait()
+}
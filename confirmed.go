@@ -0,0 +1,276 @@
+package blockstore
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ConfirmedBlock is a block with all metadata, shaped like the getBlock JSON-RPC response.
+type ConfirmedBlock struct {
+	PreviousBlockhash solana.Hash           `json:"previousBlockhash"`
+	Blockhash         solana.Hash           `json:"blockhash"`
+	ParentSlot        uint64                `json:"parentSlot"`
+	Transactions      []TransactionWithMeta `json:"transactions"`
+	Rewards           []Reward              `json:"rewards"`
+	BlockTime         *int64                `json:"blockTime"`   // nil if not recorded
+	BlockHeight       *uint64               `json:"blockHeight"` // nil if not recorded
+}
+
+// TransactionWithMeta is a transaction along with its status meta.
+type TransactionWithMeta struct {
+	Transaction Transaction            `json:"transaction"`
+	Meta        *TransactionStatusMeta `json:"meta"`
+}
+
+// TotalFees returns the sum of the fees paid by the transactions of the block.
+func (b *ConfirmedBlock) TotalFees() (total uint64) {
+	for i := range b.Transactions {
+		if meta := b.Transactions[i].Meta; meta != nil {
+			total += meta.Fee
+		}
+	}
+	return
+}
+
+// TotalComputeUnitsConsumed returns the sum of the compute units consumed by the transactions of the block.
+//
+// Returns false if any transaction lacks the compute units consumed,
+// as in ledgers created by older Solana versions.
+func (b *ConfirmedBlock) TotalComputeUnitsConsumed() (total uint64, ok bool) {
+	for i := range b.Transactions {
+		units, ok := b.Transactions[i].ComputeUnitsConsumed()
+		if !ok {
+			return 0, false
+		}
+		total += units
+	}
+	return total, true
+}
+
+// ComputeUnitsConsumed returns the compute units consumed by the transaction,
+// or false if not recorded.
+func (t *TransactionWithMeta) ComputeUnitsConsumed() (uint64, bool) {
+	return t.Meta.computeUnitsConsumed()
+}
+
+// ComputeUnitsConsumed returns the compute units consumed by the transaction,
+// or false if not recorded.
+func (t *ConfirmedTransaction) ComputeUnitsConsumed() (uint64, bool) {
+	return t.Meta.computeUnitsConsumed()
+}
+
+func (m *TransactionStatusMeta) computeUnitsConsumed() (uint64, bool) {
+	if m == nil || m.ComputeUnitsConsumed == nil {
+		return 0, false
+	}
+	return *m.ComputeUnitsConsumed, true
+}
+
+// GetBlockTime returns the estimated production time of a block as a Unix timestamp.
+func (d *DB) GetBlockTime(slot uint64) (int64, error) {
+	if err := requireCF(d.cfBlockTime, CfBlockTime); err != nil {
+		return 0, err
+	}
+	key := MakeSlotKey(slot)
+	blockTime, err := getBincode[int64](d.db, d.readOpts, d.limiter, d.cfBlockTime, key[:])
+	if err != nil {
+		return 0, err
+	}
+	return *blockTime, nil
+}
+
+// GetBlockHeightAt returns the block height of a slot.
+//
+// Returns ErrNotFound if the slot has no recorded block height.
+func (d *DB) GetBlockHeightAt(slot uint64) (uint64, error) {
+	if err := requireCF(d.cfBlockHeight, CfBlockHeight); err != nil {
+		return 0, err
+	}
+	key := MakeSlotKey(slot)
+	height, err := getBincode[uint64](d.db, d.readOpts, d.limiter, d.cfBlockHeight, key[:])
+	if err != nil {
+		return 0, err
+	}
+	return *height, nil
+}
+
+// GetRewards returns the rewards credited at the end of a block.
+//
+// Only the protobuf encoding written by Solana v1.6 and later is supported.
+func (d *DB) GetRewards(slot uint64) ([]Reward, error) {
+	if err := requireCF(d.cfRewards, CfRewards); err != nil {
+		return nil, err
+	}
+	opts := d.readOpts
+	key := MakeSlotKey(slot)
+	res, err := d.getCF(opts, d.cfRewards, key[:])
+	if err != nil {
+		return nil, err
+	}
+	defer res.Free()
+	if !res.Exists() {
+		return nil, ErrNotFound
+	}
+	rewards, err := decodeRewards(res.Data())
+	if err != nil {
+		return nil, fmt.Errorf("invalid rewards of slot %d: %w", slot, err)
+	}
+	return rewards, nil
+}
+
+// GetConfirmedBlock returns a block along with transaction statuses, rewards, and timing info.
+//
+// Block time, block height, and rewards are optional.
+// Transaction statuses are mandatory.
+func (d *DB) GetConfirmedBlock(slot uint64) (*ConfirmedBlock, error) {
+	block, err := d.GetBlock(slot)
+	if err != nil {
+		return nil, err
+	}
+	confirmed := &ConfirmedBlock{
+		PreviousBlockhash: block.PreviousBlockHash,
+		Blockhash:         block.BlockHash,
+		ParentSlot:        block.ParentSlot,
+		Transactions:      make([]TransactionWithMeta, len(block.Transactions)),
+	}
+
+	for i, tx := range block.Transactions {
+		if len(tx.Signatures) == 0 {
+			return nil, fmt.Errorf("unsigned tx %d in block %d", i, slot)
+		}
+		meta, err := d.GetTransactionStatus(slot, tx.Signatures[0])
+		if err != nil {
+			return nil, fmt.Errorf("missing status meta of tx %s: %w", tx.Signatures[0], err)
+		}
+		if len(tx.AddressTableLookups) > 0 && tx.LoadedAddresses == nil {
+			loaded := meta.LoadedAddresses
+			tx.LoadedAddresses = &loaded
+		}
+		confirmed.Transactions[i] = TransactionWithMeta{Transaction: tx, Meta: meta}
+	}
+
+	if confirmed.Rewards, err = d.GetRewards(slot); err != nil && !isMissing(err) {
+		return nil, err
+	}
+	if confirmed.Rewards == nil {
+		confirmed.Rewards = []Reward{}
+	}
+	if blockTime, err := d.GetBlockTime(slot); err == nil {
+		confirmed.BlockTime = &blockTime
+	} else if !isMissing(err) {
+		return nil, err
+	}
+	if blockHeight, err := d.GetBlockHeightAt(slot); err == nil {
+		confirmed.BlockHeight = &blockHeight
+	} else if !isMissing(err) {
+		return nil, err
+	}
+	return confirmed, nil
+}
+
+// getLastEntryHash returns the hash of the last entry in a full slot.
+//
+// Only the last data block is decoded.
+func (d *DB) getLastEntryHash(slot uint64) (solana.Hash, error) {
+	completedRanges, meta, err := d.GetCompletedRanges(slot, 0)
+	if err != nil {
+		return solana.Hash{}, err
+	}
+	if meta == nil || !meta.IsFull() || len(completedRanges) == 0 {
+		return solana.Hash{}, ErrNotFound
+	}
+	last := completedRanges[len(completedRanges)-1]
+	entries, err := d.GetEntriesInDataBlock(slot, last.StartIndex, last.EndIndex)
+	if err != nil {
+		return solana.Hash{}, err
+	}
+	if len(entries) == 0 {
+		return solana.Hash{}, ErrNotFound
+	}
+	return entries[len(entries)-1].Hash, nil
+}
+
+// isMissing returns whether err indicates absent optional data.
+func isMissing(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrColumnFamilyUnavailable)
+}
+
+// MarshalJSON encodes the status meta like the getBlock JSON-RPC response,
+// with Err decoded into the err and status fields.
+func (m TransactionStatusMeta) MarshalJSON() ([]byte, error) {
+	type metaJSON TransactionStatusMeta // drops this method
+	var txErr any
+	status := map[string]any{"Ok": nil}
+	if m.Err != nil {
+		txErr = transactionErrorJSON(m.Err)
+		status = map[string]any{"Err": txErr}
+	}
+	return json.Marshal(struct {
+		Err    any            `json:"err"`
+		Status map[string]any `json:"status"`
+		metaJSON
+	}{txErr, status, metaJSON(m)})
+}
+
+// MarshalJSON encodes the instruction like the getBlock JSON-RPC response,
+// with the data in base58.
+func (ins InnerInstruction) MarshalJSON() ([]byte, error) {
+	// Widened since []uint8 would encode as base64.
+	accounts := make([]uint16, len(ins.Accounts))
+	for i, account := range ins.Accounts {
+		accounts[i] = uint16(account)
+	}
+	return json.Marshal(struct {
+		ProgramIDIndex uint16        `json:"programIdIndex"`
+		Accounts       []uint16      `json:"accounts"`
+		Data           solana.Base58 `json:"data"`
+		StackHeight    *uint32       `json:"stackHeight"`
+	}{ins.ProgramIDIndex, accounts, ins.Data, ins.StackHeight})
+}
+
+// MarshalJSON encodes the token balance like the getBlock JSON-RPC response,
+// omitting the owner and program ID if not recorded.
+func (b TokenBalance) MarshalJSON() ([]byte, error) {
+	out := struct {
+		AccountIndex  uint8             `json:"accountIndex"`
+		Mint          solana.PublicKey  `json:"mint"`
+		UiTokenAmount UiTokenAmount     `json:"uiTokenAmount"`
+		Owner         *solana.PublicKey `json:"owner,omitempty"`
+		ProgramID     *solana.PublicKey `json:"programId,omitempty"`
+	}{AccountIndex: b.AccountIndex, Mint: b.Mint, UiTokenAmount: b.UiTokenAmount}
+	if !b.Owner.IsZero() {
+		out.Owner = &b.Owner
+	}
+	if !b.ProgramID.IsZero() {
+		out.ProgramID = &b.ProgramID
+	}
+	return json.Marshal(out)
+}
+
+// MarshalJSON encodes the amount like the getBlock JSON-RPC response,
+// where a zero UiAmount is null.
+func (a UiTokenAmount) MarshalJSON() ([]byte, error) {
+	var uiAmount *float64
+	if a.UiAmount != 0 {
+		uiAmount = &a.UiAmount
+	}
+	return json.Marshal(struct {
+		UiAmount       *float64 `json:"uiAmount"`
+		Decimals       uint8    `json:"decimals"`
+		Amount         string   `json:"amount"`
+		UiAmountString string   `json:"uiAmountString"`
+	}{uiAmount, a.Decimals, a.Amount, a.UiAmountString})
+}
+
+// MarshalJSON encodes the return data like the getBlock JSON-RPC response,
+// with the data as a [base64, "base64"] pair.
+func (r ReturnData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ProgramID solana.PublicKey `json:"programId"`
+		Data      [2]string        `json:"data"`
+	}{r.ProgramID, [2]string{base64.StdEncoding.EncodeToString(r.Data), "base64"}})
+}
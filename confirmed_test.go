@@ -0,0 +1,143 @@
+package blockstore_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+)
+
+func TestTransactionStatusMetaJSON(t *testing.T) {
+	units := uint64(1234)
+	commission := uint8(10)
+	meta := blockstore.TransactionStatusMeta{
+		// InstructionError(1, Custom(6001))
+		Err:          []byte{8, 0, 0, 0, 1, 25, 0, 0, 0, 0x71, 0x17, 0, 0},
+		Fee:          5000,
+		PreBalances:  []uint64{10},
+		PostBalances: []uint64{5},
+		InnerInstructions: []blockstore.InnerInstructions{{
+			Index: 0,
+			Instructions: []blockstore.InnerInstruction{
+				{ProgramIDIndex: 2, Accounts: []uint8{0, 1}, Data: []byte{1, 2, 3}},
+			},
+		}},
+		LogMessages: []string{"log"},
+		PreTokenBalances: []blockstore.TokenBalance{{
+			AccountIndex:  1,
+			Mint:          solana.PublicKey{1},
+			UiTokenAmount: blockstore.UiTokenAmount{Decimals: 6, Amount: "0", UiAmountString: "0"},
+		}},
+		PostTokenBalances: []blockstore.TokenBalance{},
+		Rewards: []blockstore.Reward{
+			{Pubkey: solana.PublicKey{2}, Lamports: 7, PostBalance: 8, RewardType: blockstore.RewardTypeVoting, Commission: &commission},
+		},
+		LoadedAddresses:      blockstore.LoadedAddresses{Writable: []solana.PublicKey{}, Readonly: []solana.PublicKey{}},
+		ReturnData:           &blockstore.ReturnData{ProgramID: solana.PublicKey{3}, Data: []byte{0xff}},
+		ComputeUnitsConsumed: &units,
+	}
+	got, err := json.Marshal(&meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mint := solana.PublicKey{1}.String()
+	want := `{"err":{"InstructionError":[1,{"Custom":6001}]},"status":{"Err":{"InstructionError":[1,{"Custom":6001}]}},` +
+		`"fee":5000,"preBalances":[10],"postBalances":[5],` +
+		`"innerInstructions":[{"index":0,"instructions":[{"programIdIndex":2,"accounts":[0,1],"data":"Ldp","stackHeight":null}]}],` +
+		`"logMessages":["log"],` +
+		`"preTokenBalances":[{"accountIndex":1,"mint":"` + mint + `","uiTokenAmount":{"uiAmount":null,"decimals":6,"amount":"0","uiAmountString":"0"}}],` +
+		`"postTokenBalances":[],` +
+		`"rewards":[{"pubkey":"` + solana.PublicKey{2}.String() + `","lamports":7,"postBalance":8,"rewardType":"Voting","commission":10}],` +
+		`"loadedAddresses":{"writable":[],"readonly":[]},` +
+		`"returnData":{"programId":"` + solana.PublicKey{3}.String() + `","data":["/w==","base64"]},` +
+		`"computeUnitsConsumed":1234}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestTransactionStatusMetaJSONOk(t *testing.T) {
+	got, err := json.Marshal(&blockstore.TransactionStatusMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(got, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if string(fields["err"]) != "null" || string(fields["status"]) != `{"Ok":null}` {
+		t.Errorf("got err %s, status %s", fields["err"], fields["status"])
+	}
+	for _, key := range []string{"returnData", "computeUnitsConsumed"} {
+		if _, ok := fields[key]; ok {
+			t.Errorf("unrecorded %s present", key)
+		}
+	}
+}
+
+func TestTransactionErrorJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  []byte
+		want string
+	}{
+		{name: "Unit", err: []byte{0, 0, 0, 0}, want: `"AccountInUse"`},
+		{name: "DuplicateInstruction", err: []byte{30, 0, 0, 0, 2}, want: `{"DuplicateInstruction":2}`},
+		{name: "InsufficientFundsForRent", err: []byte{31, 0, 0, 0, 3}, want: `{"InsufficientFundsForRent":{"account_index":3}}`},
+		{name: "InstructionErrorUnit", err: []byte{8, 0, 0, 0, 0, 0, 0, 0, 0}, want: `{"InstructionError":[0,"GenericError"]}`},
+		{name: "BorshIoError", err: []byte{8, 0, 0, 0, 4, 44, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 'h', 'i'}, want: `{"InstructionError":[4,{"BorshIoError":"hi"}]}`},
+		{name: "UnknownInstructionError", err: []byte{8, 0, 0, 0, 0, 0xff, 0, 0, 0}, want: `"0800000000ff000000"`},
+		{name: "Unknown", err: []byte{0xff, 0, 0, 0}, want: `"ff000000"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Marshal(&blockstore.TransactionStatusMeta{Err: tc.err})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(got, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if string(fields["err"]) != tc.want {
+				t.Errorf("got %s, want %s", fields["err"], tc.want)
+			}
+		})
+	}
+}
+
+// TestInstructionErrorDiscriminants pins variants to the discriminants of
+// solana_sdk::instruction::InstructionError.
+func TestInstructionErrorDiscriminants(t *testing.T) {
+	tests := []struct {
+		variant byte
+		want    string
+	}{
+		{0, "GenericError"},
+		{12, "ExternalAccountLamportSpend"},
+		{13, "ExternalAccountDataModified"},
+		{14, "ReadonlyLamportChange"},
+		{24, "DuplicateAccountOutOfSync"},
+		{26, "InvalidError"},
+		{37, "ComputationalBudgetExceeded"},
+		{43, "IncorrectAuthority"},
+		{45, "AccountNotRentExempt"},
+		{53, "BuiltinProgramsMustConsumeComputeUnits"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			got, err := json.Marshal(&blockstore.TransactionStatusMeta{Err: []byte{8, 0, 0, 0, 0, tc.variant, 0, 0, 0}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(got, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if want := `{"InstructionError":[0,"` + tc.want + `"]}`; string(fields["err"]) != want {
+				t.Errorf("got %s, want %s", fields["err"], want)
+			}
+		})
+	}
+}
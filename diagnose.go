@@ -0,0 +1,262 @@
+package blockstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/terorie/solana-blockstore-go/shred"
+)
+
+// SlotDiagnosis summarizes the state of a slot in the ledger.
+type SlotDiagnosis struct {
+	Slot     uint64    `yaml:"slot" json:"slot"`
+	Meta     *SlotMeta `yaml:"meta" json:"meta"` // nil if missing
+	IsFull   bool      `yaml:"is_full" json:"is_full"`
+	IsDead   bool      `yaml:"is_dead" json:"is_dead"`
+	IsRoot   bool      `yaml:"is_root" json:"is_root"`
+	IsOrphan bool      `yaml:"is_orphan" json:"is_orphan"` // parent slot unknown
+
+	NumDataShreds     uint64   `yaml:"num_data_shreds" json:"num_data_shreds"`
+	MissingDataShreds []uint64 `yaml:"missing_data_shreds" json:"missing_data_shreds"`
+	FirstMissingShred *uint64  `yaml:"first_missing_shred" json:"first_missing_shred"` // nil if none missing
+	HasCodingShreds   bool     `yaml:"has_coding_shreds" json:"has_coding_shreds"`
+}
+
+// DiagnoseSlot inspects the meta and shreds of a slot,
+// e.g. to find out why GetBlock fails.
+//
+// Data shreds are checked up to the last index if known,
+// or up to the highest received index otherwise.
+func (d *DB) DiagnoseSlot(slot uint64) (*SlotDiagnosis, error) {
+	diag := &SlotDiagnosis{Slot: slot}
+
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		diag.Meta = meta
+		diag.IsFull = meta.IsFull()
+		diag.IsOrphan = meta.ParentSlotOpt() == nil
+	}
+	if diag.IsDead, err = d.IsSlotDead(slot); err != nil {
+		return nil, err
+	}
+	if diag.IsRoot, err = d.IsRoot(slot); err != nil {
+		return nil, err
+	}
+
+	var end uint64
+	if diag.Meta != nil {
+		end = diag.Meta.Received
+		if lastIndex := diag.Meta.LastIndexOpt(); lastIndex != nil {
+			end = *lastIndex + 1
+		}
+	}
+	iter := d.IterDataShredsForSlot(slot)
+	next := uint64(0)
+	for ; iter.Valid(); iter.Next() {
+		_, index := iter.Key()
+		for ; next < index && next < end; next++ {
+			diag.MissingDataShreds = append(diag.MissingDataShreds, next)
+		}
+		next = index + 1
+		diag.NumDataShreds++
+	}
+	iter.Close()
+	for ; next < end; next++ {
+		diag.MissingDataShreds = append(diag.MissingDataShreds, next)
+	}
+	if len(diag.MissingDataShreds) > 0 {
+		diag.FirstMissingShred = &diag.MissingDataShreds[0]
+	}
+
+	codeIter := d.iterShreds(nil, d.cfCodeShred)
+	defer codeIter.Close()
+	prefix := MakeSlotKey(slot)
+	codeIter.Seek(prefix[:])
+	if codeIter.Valid() {
+		key := codeIter.Key().Data()
+		diag.HasCodingShreds = bytes.HasPrefix(key, prefix[:])
+	}
+	return diag, nil
+}
+
+// ShredRequest identifies a shred to request from peers via repair.
+type ShredRequest struct {
+	Slot  uint64 `yaml:"slot" json:"slot"`
+	Index uint64 `yaml:"index" json:"index"`
+
+	// IsCoding is always false for now,
+	// as the repair protocol only serves data shreds.
+	IsCoding bool `yaml:"is_coding" json:"is_coding"`
+
+	// Highest requests any shred with an index of at least Index,
+	// used when the last shred of the slot is not yet known.
+	Highest bool `yaml:"highest" json:"highest"`
+
+	// FECSetIndex is the erasure set of the shred,
+	// if inferable from neighboring shreds of the same set.
+	FECSetIndex *uint32 `yaml:"fec_set_index" json:"fec_set_index"`
+}
+
+// RepairNeeds returns the shreds that need to be repaired to complete a slot.
+//
+// Matches the validator's repair logic:
+// Full and dead slots need no repair.
+// If no gaps are known, the highest shred is requested.
+// Otherwise, each missing data shred in [Consumed, Received) is requested.
+func (d *DB) RepairNeeds(slot uint64) ([]ShredRequest, error) {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return nil, err
+	}
+	if meta.IsFull() {
+		return nil, nil
+	}
+	isDead, err := d.IsSlotDead(slot)
+	if err != nil {
+		return nil, err
+	}
+	if isDead {
+		return nil, nil
+	}
+	if meta.Consumed == meta.Received {
+		return []ShredRequest{{Slot: slot, Index: meta.Received, Highest: true}}, nil
+	}
+
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	start := MakeShredKey(slot, meta.Consumed)
+	iter.Seek(start[:])
+
+	var requests []ShredRequest
+	var prevFECSet *uint32
+	next := meta.Consumed
+	for ; iter.Valid() && next < meta.Received; iter.Next() {
+		_, index := iter.Key()
+		value := iter.Value().Data()
+		d.limiter.charge(len(value))
+		fecSet, err := parseFECSetIndex(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data shred %d/%d: %w", slot, index, err)
+		}
+		gapStart := len(requests)
+		for ; next < index && next < meta.Received; next++ {
+			requests = append(requests, ShredRequest{Slot: slot, Index: next})
+		}
+		// The gap is part of an erasure set if it is surrounded by shreds of that set.
+		if prevFECSet != nil && *prevFECSet == fecSet {
+			for i := gapStart; i < len(requests); i++ {
+				requests[i].FECSetIndex = prevFECSet
+			}
+		}
+		prevFECSet = &fecSet
+		next = index + 1
+	}
+	for ; next < meta.Received; next++ {
+		requests = append(requests, ShredRequest{Slot: slot, Index: next})
+	}
+	return requests, nil
+}
+
+// parseFECSetIndex reads the erasure set index from the common header of a shred.
+func parseFECSetIndex(payload []byte) (uint32, error) {
+	var header shred.CommonHeader
+	if err := bin.NewBinDecoder(payload).Decode(&header); err != nil {
+		return 0, err
+	}
+	return header.FECSetIndex, nil
+}
+
+// SlotMetaVerification lists discrepancies between a slot meta and the data shreds present in the ledger.
+type SlotMetaVerification struct {
+	Slot uint64    `yaml:"slot" json:"slot"`
+	Meta *SlotMeta `yaml:"meta" json:"meta"`
+
+	// ActualConsumed is the index of the first missing data shred.
+	ActualConsumed uint64 `yaml:"actual_consumed" json:"actual_consumed"`
+	// ActualReceived is one past the highest data shred index present.
+	ActualReceived uint64 `yaml:"actual_received" json:"actual_received"`
+
+	// MissingShreds are the data shreds below Meta.Consumed that are absent.
+	MissingShreds []uint64 `yaml:"missing_shreds" json:"missing_shreds"`
+	// ExtraShreds are the data shreds present at or beyond Meta.Received.
+	ExtraShreds []uint64 `yaml:"extra_shreds" json:"extra_shreds"`
+	// InvalidCompletedIndexes are the entries of Meta.CompletedDataIndexes
+	// whose data shred is absent or not flagged as completing a data block.
+	InvalidCompletedIndexes []uint32 `yaml:"invalid_completed_indexes" json:"invalid_completed_indexes"`
+}
+
+// Consistent returns whether no discrepancies were found.
+func (v *SlotMetaVerification) Consistent() bool {
+	return v.ActualConsumed == v.Meta.Consumed &&
+		v.ActualReceived == v.Meta.Received &&
+		len(v.MissingShreds) == 0 &&
+		len(v.ExtraShreds) == 0 &&
+		len(v.InvalidCompletedIndexes) == 0
+}
+
+// VerifySlotMeta cross-checks the slot meta against the data shreds of a slot,
+// e.g. to detect corruption or truncation of a ledger.
+//
+// Returns ErrNotFound if the slot has no meta.
+func (d *DB) VerifySlotMeta(slot uint64) (*SlotMetaVerification, error) {
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return nil, err
+	}
+	v := &SlotMetaVerification{Slot: slot, Meta: meta}
+
+	completed := make(map[uint64]bool) // data shreds flagged as completing a data block
+	iter := d.IterDataShredsForSlot(slot)
+	defer iter.Close()
+	next := uint64(0)
+	consumedKnown := false
+	for ; iter.Valid(); iter.Next() {
+		_, index := iter.Key()
+		if index != next && !consumedKnown {
+			v.ActualConsumed, consumedKnown = next, true
+		}
+		for ; next < index && next < meta.Consumed; next++ {
+			v.MissingShreds = append(v.MissingShreds, next)
+		}
+		if index >= meta.Received {
+			v.ExtraShreds = append(v.ExtraShreds, index)
+		}
+
+		value := iter.Value().Data()
+		d.limiter.charge(len(value))
+		dec := bin.NewBinDecoder(value)
+		var common shred.CommonHeader
+		var header shred.DataHeader
+		if err := dec.Decode(&common); err != nil {
+			return nil, fmt.Errorf("invalid data shred %d/%d: %w", slot, index, err)
+		}
+		if err := dec.Decode(&header); err != nil {
+			return nil, fmt.Errorf("invalid data shred %d/%d: %w", slot, index, err)
+		}
+		completed[index] = header.Flags&shred.FlagDataCompleteShred != 0
+
+		next = index + 1
+		v.ActualReceived = next
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	if !consumedKnown {
+		v.ActualConsumed = next
+	}
+	for ; next < meta.Consumed; next++ {
+		v.MissingShreds = append(v.MissingShreds, next)
+	}
+
+	for _, index := range meta.CompletedDataIndexes {
+		if !completed[uint64(index)] {
+			v.InvalidCompletedIndexes = append(v.InvalidCompletedIndexes, index)
+		}
+	}
+	return v, nil
+}
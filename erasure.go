@@ -0,0 +1,78 @@
+package blockstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/terorie/solana-blockstore-go/shred"
+)
+
+// GetErasureMeta returns the metadata of the erasure set starting at the given shred index.
+func (d *DB) GetErasureMeta(slot uint64, fecSetIndex uint64) (*ErasureMeta, error) {
+	key := MakeShredKey(slot, fecSetIndex)
+	return getBincode[ErasureMeta](d.db, d.readOpts, d.limiter, d.cfErasureMeta, key[:])
+}
+
+// findErasureMeta returns the metadata of the erasure set containing a data shred.
+func (d *DB) findErasureMeta(slot uint64, index uint64) (*ErasureMeta, error) {
+	if err := requireCF(d.cfErasureMeta, CfErasureMeta); err != nil {
+		return nil, err
+	}
+	iter := d.db.NewIteratorCF(d.iterOpts, d.cfErasureMeta)
+	defer iter.Close()
+	key := MakeShredKey(slot, index)
+	iter.SeekForPrev(key[:])
+	if !iter.Valid() || !bytes.HasPrefix(iter.Key().Data(), key[:8]) {
+		return nil, ErrNotFound
+	}
+	value := iter.Value().Data()
+	d.limiter.charge(len(value))
+	meta, err := ParseBincode[ErasureMeta](value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid erasure meta: %w", err)
+	}
+	if index >= meta.SetIndex+meta.Config.NumData {
+		return nil, ErrNotFound
+	}
+	return meta, nil
+}
+
+// recoverErasureSet recovers the missing data shreds of the erasure set
+// containing the given data shred index.
+func (d *DB) recoverErasureSet(ctx context.Context, slot uint64, index uint64) (_ []shred.Shred, err error) {
+	_, span := d.startSpan(ctx, "RecoverErasureSet", slot)
+	defer func() { span.End(err) }()
+
+	meta, err := d.findErasureMeta(slot, index)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find erasure set: %w", err)
+	}
+	data, err := d.getShredRange(d.IterDataShredsForSlot(slot), slot, meta.SetIndex, meta.Config.NumData)
+	if err != nil {
+		return nil, err
+	}
+	coding, err := d.getShredRange(d.IterCodingShredsForSlot(slot), slot, meta.FirstCodingIndex, meta.Config.NumCoding)
+	if err != nil {
+		return nil, err
+	}
+	return shred.RecoverWithConfig(data, coding, meta.Config.shredConfig())
+}
+
+// getShredRange parses the available shreds in the index range [start, start+n).
+func (d *DB) getShredRange(iter *ShredIterator, slot uint64, start uint64, n uint64) ([]shred.Shred, error) {
+	defer iter.Close()
+	key := MakeShredKey(slot, start)
+	var shreds []shred.Shred
+	for iter.Seek(key[:]); iter.Valid(); iter.Next() {
+		index, s, err := iter.Shred()
+		if index >= start+n {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize shred %d/%d: %w", slot, index, err)
+		}
+		shreds = append(shreds, s)
+	}
+	return shreds, nil
+}
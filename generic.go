@@ -17,19 +17,36 @@ func ParseBincode[T any](data []byte) (*T, error) {
 
 func GetBincode[T any](db *grocksdb.DB, cf *grocksdb.ColumnFamilyHandle, key []byte) (*T, error) {
 	opts := grocksdb.NewDefaultReadOptions()
+	defer opts.Destroy()
+	return getBincode[T](db, opts, nil, cf, key)
+}
+
+func getBincode[T any](db *grocksdb.DB, opts *grocksdb.ReadOptions, limiter *readLimiter, cf *grocksdb.ColumnFamilyHandle, key []byte) (*T, error) {
+	if cf == nil {
+		return nil, ErrColumnFamilyUnavailable
+	}
 	res, err := db.GetCF(opts, cf, key)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Free()
 	if !res.Exists() {
 		return nil, ErrNotFound
 	}
-	defer res.Free()
+	limiter.charge(res.Size())
 	return ParseBincode[T](res.Data())
 }
 
 func MultiGetBincode[T any](db *grocksdb.DB, cf *grocksdb.ColumnFamilyHandle, key ...[]byte) ([]*T, error) {
 	opts := grocksdb.NewDefaultReadOptions()
+	defer opts.Destroy()
+	return multiGetBincode[T](db, opts, nil, cf, key...)
+}
+
+func multiGetBincode[T any](db *grocksdb.DB, opts *grocksdb.ReadOptions, limiter *readLimiter, cf *grocksdb.ColumnFamilyHandle, key ...[]byte) ([]*T, error) {
+	if cf == nil {
+		return nil, ErrColumnFamilyUnavailable
+	}
 	rows, err := db.MultiGetCF(opts, cf, key...)
 	if err != nil {
 		return nil, err
@@ -38,10 +55,10 @@ func MultiGetBincode[T any](db *grocksdb.DB, cf *grocksdb.ColumnFamilyHandle, ke
 
 	vals := make([]*T, len(rows))
 	for i, row := range rows {
+		limiter.charge(row.Size())
 		val, err := ParseBincode[T](row.Data())
 		if err != nil {
-			fmt.Printf("cannot decode %s: %s", hex.EncodeToString(key[i]), err)
-			return nil, err
+			return nil, fmt.Errorf("cannot decode %s: %w", hex.EncodeToString(key[i]), err)
 		}
 		vals[i] = val
 	}
@@ -1,11 +1,145 @@
 package blockstore
 
-import "github.com/linxGnu/grocksdb"
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/linxGnu/grocksdb"
+	"github.com/terorie/solana-blockstore-go/shred"
+)
 
 type IterBincode[T any] struct {
 	*grocksdb.Iterator
+	opts    *grocksdb.ReadOptions // owned by the iterator, nil if provided by the caller
+	limiter *readLimiter
+}
+
+// Close releases the iterator and its read options, if owned.
+func (i IterBincode[T]) Close() {
+	i.Iterator.Close()
+	if i.opts != nil {
+		i.opts.Destroy()
+	}
+}
+
+// newIterBincode creates an iterator over a column family with the given options.
+func newIterBincode[T any](d *DB, o *IterOptions, cf *grocksdb.ColumnFamilyHandle) IterBincode[T] {
+	opts := d.newUserIterReadOptions(o)
+	iter := IterBincode[T]{Iterator: d.db.NewIteratorCF(opts, cf), limiter: d.limiter}
+	if o != nil {
+		iter.opts = opts
+	}
+	return iter
 }
 
 func (i IterBincode[T]) Element() (*T, error) {
-	return ParseBincode[T](i.Value().Data())
+	value := i.Value().Data()
+	i.limiter.charge(len(value))
+	return ParseBincode[T](value)
+}
+
+// SlotMetaIterator iterates over CfMeta.
+type SlotMetaIterator struct {
+	IterBincode[SlotMeta]
+}
+
+// SlotMeta returns the slot number and the slot meta at the current position.
+func (i SlotMetaIterator) SlotMeta() (uint64, *SlotMeta, error) {
+	slot, err := ParseSlotKey(i.Key().Data())
+	if err != nil {
+		return 0, nil, err
+	}
+	meta, err := i.Element()
+	if err != nil {
+		return slot, nil, err
+	}
+	meta.Slot = slot
+	return slot, meta, nil
+}
+
+// RootIterator iterates over CfRoot.
+type RootIterator struct {
+	IterBincode[bool]
+}
+
+// Slot returns the rooted slot at the current position.
+func (i RootIterator) Slot() (uint64, error) {
+	return ParseSlotKey(i.Key().Data())
+}
+
+// BlockHeightIterator iterates over CfBlockHeight.
+type BlockHeightIterator struct {
+	IterBincode[uint64]
+}
+
+// BlockHeight returns the slot number and its block height at the current position.
+func (i BlockHeightIterator) BlockHeight() (slot uint64, height uint64, err error) {
+	slot, err = ParseSlotKey(i.Key().Data())
+	if err != nil {
+		return 0, 0, err
+	}
+	value := i.Value().Data()
+	if len(value) != 8 {
+		return slot, 0, fmt.Errorf("invalid block height length %d", len(value))
+	}
+	return slot, binary.LittleEndian.Uint64(value), nil
+}
+
+// IterShred iterates over CfDataShred or CfCodeShred.
+//
+// Key shadows the raw key accessor of the embedded iterator.
+type IterShred struct {
+	*grocksdb.Iterator
+	opts    *grocksdb.ReadOptions // owned by the iterator, nil if shared
+	limiter *readLimiter
+	closed  bool
+}
+
+// Close releases the iterator and its read options, if owned.
+//
+// Calling Close more than once has no effect.
+func (i *IterShred) Close() {
+	if i.closed {
+		return
+	}
+	i.closed = true
+	i.Iterator.Close()
+	if i.opts != nil {
+		i.opts.Destroy()
+	}
+}
+
+// Element parses the shred at the current position.
+func (i IterShred) Element() (shred.Shred, error) {
+	value := i.Value().Data()
+	i.limiter.charge(len(value))
+	return shred.NewShredFromSerializedErr(value)
+}
+
+// Key returns the slot number and shred index at the current position.
+//
+// Returns zeros for malformed keys, see KeyErr.
+func (i IterShred) Key() (slot, index uint64) {
+	slot, index, _ = i.KeyErr()
+	return
+}
+
+// KeyErr is like Key but returns an error wrapping ErrInvalidShredData for malformed keys.
+func (i IterShred) KeyErr() (slot, index uint64, err error) {
+	return ParseShredKey(i.Iterator.Key().Data())
+}
+
+// ShredIterator iterates over the shreds of a single slot.
+type ShredIterator struct {
+	IterShred
+}
+
+// Shred returns the index and the parsed shred at the current position.
+func (i *ShredIterator) Shred() (uint64, shred.Shred, error) {
+	_, index, err := i.KeyErr()
+	if err != nil {
+		return 0, nil, err
+	}
+	s, err := i.Element()
+	return index, s, err
 }
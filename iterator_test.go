@@ -0,0 +1,56 @@
+package blockstore_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+func TestIterDataShredsOptions(t *testing.T) {
+	b := testutil.NewLedger(t)
+	b.AddBlock(1, 0, []blockstore.Entry{{NumHashes: 1, Hash: solana.Hash{1}}})
+	b.AddBlock(2, 1, []blockstore.Entry{{NumHashes: 1, Hash: solana.Hash{2}}})
+	db, err := blockstore.OpenReadOnly(b.Path())
+	if err != nil {
+		t.Fatalf("cannot open ledger: %s", err)
+	}
+	defer db.Close()
+
+	upper := blockstore.MakeSlotKey(2)
+	tests := []struct {
+		name string
+		opts *blockstore.IterOptions
+		want int
+	}{
+		{name: "Nil", opts: nil, want: 2},
+		{name: "Default", opts: blockstore.DefaultIterOptions(), want: 2},
+		{name: "Zero", opts: &blockstore.IterOptions{}, want: 2},
+		{name: "UpperBound", opts: &blockstore.IterOptions{UpperBound: upper[:]}, want: 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			iter := db.IterDataShreds(tc.opts)
+			n := 0
+			for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+				if _, err := iter.Element(); err != nil {
+					t.Errorf("invalid shred: %s", err)
+				}
+				n++
+			}
+			iter.Close()
+			iter.Close()
+			if n != tc.want {
+				t.Errorf("iterated %d shreds, want %d", n, tc.want)
+			}
+		})
+	}
+
+	shreds := db.IterDataShredsForSlot(2)
+	if !shreds.Valid() {
+		t.Fatal("no shreds in slot 2")
+	}
+	shreds.Close()
+	shreds.Close()
+}
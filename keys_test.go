@@ -0,0 +1,62 @@
+package blockstore_test
+
+import (
+	"errors"
+	"testing"
+
+	blockstore "github.com/terorie/solana-blockstore-go"
+)
+
+func TestParseShredKey(t *testing.T) {
+	valid := blockstore.MakeShredKey(0x0102030405060708, 42)
+	tests := []struct {
+		name  string
+		key   []byte
+		slot  uint64
+		index uint64
+		err   error
+	}{
+		{name: "Valid", key: valid[:], slot: 0x0102030405060708, index: 42},
+		{name: "Empty", key: nil, err: blockstore.ErrInvalidShredData},
+		{name: "Short", key: valid[:15], err: blockstore.ErrInvalidShredData},
+		{name: "Long", key: append(valid[:], 0), err: blockstore.ErrInvalidShredData},
+		{name: "SlotKey", key: valid[:8], err: blockstore.ErrInvalidShredData},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slot, index, err := blockstore.ParseShredKey(tc.key)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if slot != tc.slot || index != tc.index {
+				t.Errorf("got (%d, %d), want (%d, %d)", slot, index, tc.slot, tc.index)
+			}
+		})
+	}
+}
+
+func TestParseSlotKey(t *testing.T) {
+	valid := blockstore.MakeSlotKey(0x0102030405060708)
+	tests := []struct {
+		name string
+		key  []byte
+		slot uint64
+		err  error
+	}{
+		{name: "Valid", key: valid[:], slot: 0x0102030405060708},
+		{name: "Empty", key: nil, err: blockstore.ErrInvalidKey},
+		{name: "Short", key: valid[:7], err: blockstore.ErrInvalidKey},
+		{name: "Long", key: append(valid[:], 0), err: blockstore.ErrInvalidKey},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			slot, err := blockstore.ParseSlotKey(tc.key)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if slot != tc.slot {
+				t.Errorf("got slot %d, want %d", slot, tc.slot)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package blockstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/chacha20"
+)
+
+// NumConsecutiveLeaderSlots is the number of consecutive slots assigned to a leader.
+const NumConsecutiveLeaderSlots = 4
+
+var ErrNoStake = errors.New("no staked nodes")
+
+// ComputeLeaderSchedule reconstructs the leader of each slot in an epoch.
+//
+// epochStakes maps node identities to their delegated stake at the epoch's stakes snapshot.
+// Matches leader_schedule_utils::leader_schedule of the Solana validator,
+// which samples leaders weighted by stake using a ChaCha20 RNG seeded with the epoch.
+func ComputeLeaderSchedule(epochStakes map[solana.PublicKey]uint64, epoch uint64, slotsPerEpoch uint64) ([]solana.PublicKey, error) {
+	type nodeStake struct {
+		id    solana.PublicKey
+		stake uint64
+	}
+	nodes := make([]nodeStake, 0, len(epochStakes))
+	for id, stake := range epochStakes {
+		// Unstaked nodes are never sampled.
+		if stake > 0 {
+			nodes = append(nodes, nodeStake{id, stake})
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, ErrNoStake
+	}
+	// Sort by stake descending, then by identity descending.
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].stake != nodes[j].stake {
+			return nodes[i].stake > nodes[j].stake
+		}
+		return bytes.Compare(nodes[i].id[:], nodes[j].id[:]) > 0
+	})
+
+	// Emulates rand 0.7's WeightedIndex<u64>.
+	cumulative := make([]uint64, len(nodes))
+	var total uint64
+	for i, node := range nodes {
+		total += node.stake
+		cumulative[i] = total
+	}
+
+	var seed [32]byte
+	binary.LittleEndian.PutUint64(seed[:8], epoch)
+	rng, err := newChaChaRng(seed)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := make([]solana.PublicKey, slotsPerEpoch)
+	var current solana.PublicKey
+	for i := range schedule {
+		if i%NumConsecutiveLeaderSlots == 0 {
+			chosen := rng.uniformUint64(total)
+			index := sort.Search(len(cumulative), func(j int) bool {
+				return cumulative[j] > chosen
+			})
+			current = nodes[index].id
+		}
+		schedule[i] = current
+	}
+	return schedule, nil
+}
+
+// GetSlotLeader returns the leader of a slot given its epoch's leader schedule.
+// Returns false if the schedule is empty.
+//
+// Assumes an epoch schedule without warmup, where all epochs have len(schedule) slots.
+func GetSlotLeader(schedule []solana.PublicKey, slot uint64) (solana.PublicKey, bool) {
+	if len(schedule) == 0 {
+		return solana.PublicKey{}, false
+	}
+	return schedule[slot%uint64(len(schedule))], true
+}
+
+// chachaRng emulates rand_chacha 0.2's ChaChaRng, a ChaCha20 keystream with zero nonce.
+type chachaRng struct {
+	cipher *chacha20.Cipher
+	buf    [8]byte
+}
+
+func newChaChaRng(seed [32]byte) (*chachaRng, error) {
+	var nonce [chacha20.NonceSize]byte
+	cipher, err := chacha20.NewUnauthenticatedCipher(seed[:], nonce[:])
+	if err != nil {
+		return nil, err
+	}
+	return &chachaRng{cipher: cipher}, nil
+}
+
+func (r *chachaRng) nextUint64() uint64 {
+	r.buf = [8]byte{}
+	r.cipher.XORKeyStream(r.buf[:], r.buf[:])
+	return binary.LittleEndian.Uint64(r.buf[:])
+}
+
+// uniformUint64 returns a uniform random number in [0, n)
+// using the widening multiply rejection method of rand 0.7's UniformInt<u64>.
+func (r *chachaRng) uniformUint64(n uint64) uint64 {
+	reject := (-n) % n // (MaxUint64 - n + 1) % n
+	zone := ^uint64(0) - reject
+	for {
+		hi, lo := bits.Mul64(r.nextUint64(), n)
+		if lo <= zone {
+			return hi
+		}
+	}
+}
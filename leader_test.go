@@ -0,0 +1,18 @@
+package blockstore_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+)
+
+func TestGetSlotLeader(t *testing.T) {
+	schedule := []solana.PublicKey{{1}, {2}, {3}}
+	if got, ok := blockstore.GetSlotLeader(schedule, 7); !ok || got != schedule[1] {
+		t.Errorf("leader of slot 7 is %s, %v", got, ok)
+	}
+	if _, ok := blockstore.GetSlotLeader(nil, 7); ok {
+		t.Error("got leader from empty schedule")
+	}
+}
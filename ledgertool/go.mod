@@ -4,6 +4,7 @@ go 1.18
 
 require (
 	github.com/dfuse-io/logging v0.0.0-20201110202154-26697de88c79
+	github.com/gagliardetto/solana-go v1.5.0
 	github.com/linxGnu/grocksdb v1.7.5
 	github.com/segmentio/textio v1.2.0
 	github.com/spf13/pflag v1.0.5
@@ -12,6 +13,8 @@ require (
 	gopkg.in/yaml.v3 v3.0.1
 )
 
+replace github.com/gagliardetto/solana-go => github.com/terorie/solana-go v1.5.1-0.20220813194751-d850995ca5fb
+
 require (
 	contrib.go.opencensus.io/exporter/stackdriver v0.12.6 // indirect
 	github.com/blendle/zapdriver v1.3.1 // indirect
@@ -2,22 +2,26 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/dfuse-io/logging"
+	"github.com/gagliardetto/solana-go"
 	"github.com/linxGnu/grocksdb"
-	"github.com/segmentio/textio"
 	"github.com/spf13/pflag"
 	blockstore "github.com/terorie/solana-blockstore-go"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 )
 
 func main() {
@@ -25,18 +29,28 @@ func main() {
 		flagDBPath             string
 		flagListColumnFamilies bool
 		flagRoot               bool
+		flagRoots              string
 		flagHeight             bool
+		flagDeadSlots          bool
 		flagAllSlots           bool
 		flagSlotMetas          []uint
+		flagSlotRange          string
 		flagBlock              uint64
 		flagGetDataShred       string
 		flagGetCodeShred       string
+		flagTxStatus           string
+		flagAddress            string
+		flagLimit              int
+		flagFormat             string
+		flagVerify             string
+		flagExportBlocks       string
+		flagOut                string
 	)
 
 	pflag.Usage = func() {
 		fmt.Fprint(flag.CommandLine.Output(), `USAGE
     ledgertool extracts info from a Solana ledger blockstore (RocksDB).
-    Requested info is dumped in YAML format.
+    Requested info is dumped in YAML (default) or JSON format.
 
 AUTHOR
     Richard Patel <me@terorie.dev>
@@ -46,14 +60,25 @@ FLAGS
 		pflag.PrintDefaults()
 	}
 	pflag.StringVar(&flagDBPath, "db", "", "Path to ledger/rocksdb dir (required)")
+	pflag.StringVar(&flagFormat, "format", formatYAML, "Output `format` (yaml, json)")
 	pflag.BoolVar(&flagListColumnFamilies, "list-cfs", false, "List column families")
 	pflag.BoolVar(&flagRoot, "root", false, "Show root slot")
+	pflag.StringVar(&flagRoots, "roots", "", "List rooted slots, optionally in range `first:last` (inclusive)")
+	pflag.Lookup("roots").NoOptDefVal = allRoots
 	pflag.BoolVar(&flagHeight, "height", false, "Show block height")
+	pflag.BoolVar(&flagDeadSlots, "dead-slots", false, "List dead slots")
 	pflag.BoolVar(&flagAllSlots, "all-slots", false, "Get all slot metadatas")
 	pflag.UintSliceVar(&flagSlotMetas, "slot", nil, "Get slot metadata")
+	pflag.StringVar(&flagSlotRange, "slot-range", "", "Get slot metadatas in range `first:last` (inclusive)")
 	pflag.Uint64Var(&flagBlock, "block", 0, "Get block")
 	pflag.StringVar(&flagGetDataShred, "data-shreds", "", "Dump data shreds (space-separated list of `slot` or `slot:index`)")
 	pflag.StringVar(&flagGetCodeShred, "coding-shreds", "", "Dump coding shreds")
+	pflag.StringVar(&flagTxStatus, "tx-status", "", "Get transaction status by `signature`")
+	pflag.StringVar(&flagAddress, "address", "", "Get signatures of rooted transactions loading `pubkey`")
+	pflag.IntVar(&flagLimit, "limit", 1000, "Max number of signatures returned by --address")
+	pflag.StringVar(&flagExportBlocks, "export-blocks", "", "Export rooted blocks in range `first:last` (inclusive) as NDJSON")
+	pflag.StringVar(&flagOut, "out", "-", "Output `file` of --export-blocks (- for stdout)")
+	pflag.StringVar(&flagVerify, "verify", "", "Verify rooted full slots in range `first:last` (inclusive)")
 	pflag.Parse()
 
 	if pflag.NArg() > 0 {
@@ -65,6 +90,14 @@ FLAGS
 		fmt.Fprintln(flag.CommandLine.Output(), "missing --db flag")
 		os.Exit(2)
 	}
+	switch flagFormat {
+	case formatYAML, formatJSON:
+		outputFormat = flagFormat
+	default:
+		flag.Usage()
+		fmt.Fprintln(flag.CommandLine.Output(), "invalid --format:", flagFormat)
+		os.Exit(2)
+	}
 
 	logConfig := zap.NewDevelopmentConfig()
 	logConfig.Level.SetLevel(zap.DebugLevel)
@@ -89,11 +122,19 @@ FLAGS
 	if flagRoot {
 		ok = ok && showRoot(db)
 	}
+	if flagRoots != "" {
+		ok = ok && listRoots(db, flagRoots)
+	}
 	if flagHeight {
 		ok = ok && showBlockHeight(db)
 	}
+	if flagDeadSlots {
+		ok = ok && showDeadSlots(db)
+	}
 	if flagAllSlots {
 		ok = ok && getAllSlotMetas(db)
+	} else if flagSlotRange != "" {
+		ok = ok && getSlotMetaRange(db, flagSlotRange)
 	} else if len(flagSlotMetas) > 0 {
 		ok = ok && getSlotMetas(db, flagSlotMetas)
 	}
@@ -106,6 +147,18 @@ FLAGS
 	if flagGetCodeShred != "" {
 		ok = ok && getShreds(db, flagGetDataShred, true)
 	}
+	if flagTxStatus != "" {
+		ok = ok && getTxStatus(db, flagTxStatus)
+	}
+	if flagAddress != "" {
+		ok = ok && getSignaturesForAddress(db, flagAddress, flagLimit)
+	}
+	if flagVerify != "" {
+		ok = ok && verifySlotRange(db, flagVerify)
+	}
+	if flagExportBlocks != "" {
+		ok = ok && exportBlocks(db, flagExportBlocks, flagOut)
+	}
 
 	if !ok {
 		os.Exit(1)
@@ -119,10 +172,7 @@ func listColumnFamilies(path string) bool {
 		log.Print("Failed to list column families: ", err)
 		return false
 	}
-	fmt.Println("column_families:")
-	for _, name := range names {
-		fmt.Println("  - " + name)
-	}
+	emit("column_families", names)
 	return true
 }
 
@@ -132,17 +182,62 @@ func showRoot(db *blockstore.DB) bool {
 		log.Print("Failed to get root: ", err)
 		return false
 	}
-	fmt.Println("root:", root)
+	emit("root", root)
 	return true
 }
 
+// allRoots is the value of --roots given without a range.
+const allRoots = "all"
+
+func listRoots(db *blockstore.DB, rangeStr string) (ok bool) {
+	first, last := uint64(0), uint64(math.MaxUint64)
+	if rangeStr != allRoots {
+		if first, last, ok = parseSlotRange(rangeStr); !ok {
+			log.Print("Invalid slot range: ", rangeStr)
+			return false
+		}
+	}
+
+	iter := db.IterRoots(first, last)
+	defer iter.Close()
+
+	ok = true
+	roots := []uint64{}
+	for ; iter.Valid(); iter.Next() {
+		slot, err := iter.Slot()
+		if err != nil {
+			log.Printf("While ranging roots (%x): %s", iter.Key().Data(), err)
+			ok = false
+			continue
+		}
+		roots = append(roots, slot)
+	}
+	if err := iter.Err(); err != nil {
+		log.Print("Failed to list roots: ", err)
+		return false
+	}
+
+	emit("roots", roots)
+	return ok
+}
+
 func showBlockHeight(db *blockstore.DB) bool {
 	height, err := db.GetBlockHeight()
 	if err != nil {
 		log.Print("Failed to get block height: ", err)
 		return false
 	}
-	fmt.Println("block_height:", height)
+	emit("block_height", height)
+	return true
+}
+
+func showDeadSlots(db *blockstore.DB) bool {
+	slots, err := db.GetDeadSlots()
+	if err != nil {
+		log.Print("Failed to get dead slots: ", err)
+		return false
+	}
+	emit("dead_slots", slots)
 	return true
 }
 
@@ -162,9 +257,41 @@ func parseShredIndex(shredStr string) (slot, index uint64, ok bool) {
 	return
 }
 
+// parseSlotRange parses an inclusive slot range `first:last`.
+func parseSlotRange(rangeStr string) (first, last uint64, ok bool) {
+	first, last, ok = parseShredIndex(rangeStr)
+	ok = ok && first <= last
+	return
+}
+
+func getSlotMetaRange(db *blockstore.DB, rangeStr string) (ok bool) {
+	first, last, ok := parseSlotRange(rangeStr)
+	if !ok {
+		log.Print("Invalid slot range: ", rangeStr)
+		return false
+	}
+
+	iter := db.BoundedIterSlotMetas(first, last)
+	defer iter.Close()
+
+	metaMap := make(map[uint64]*blockstore.SlotMeta)
+	for ; iter.Valid(); iter.Next() {
+		slot, meta, err := iter.SlotMeta()
+		if err != nil {
+			log.Printf("While ranging slot metas (%x): %s", iter.Key().Data(), err)
+			ok = false
+			continue
+		}
+		metaMap[slot] = meta
+	}
+
+	dumpSlots(metaMap)
+	return ok
+}
+
 func getAllSlotMetas(db *blockstore.DB) (ok bool) {
 	ok = true
-	iter := db.IterSlotMetas(grocksdb.NewDefaultReadOptions())
+	iter := db.IterSlotMetas(nil)
 	defer iter.Close()
 
 	// Get low bound
@@ -177,13 +304,7 @@ func getAllSlotMetas(db *blockstore.DB) (ok bool) {
 	// Collect all slots to map
 	metaMap := make(map[uint64]*blockstore.SlotMeta)
 	for iter.SeekToFirst(); iter.Valid(); iter.Next() {
-		slot, err := blockstore.ParseSlotKey(iter.Key().Data())
-		if err != nil {
-			log.Printf("Ignoring slot meta key: %x", iter.Key().Data())
-			ok = false
-			continue
-		}
-		meta, err := iter.Element()
+		slot, meta, err := iter.SlotMeta()
 		if err != nil {
 			log.Printf("While ranging slot metas (%x): %s", iter.Key().Data(), err)
 			ok = false
@@ -197,9 +318,7 @@ func getAllSlotMetas(db *blockstore.DB) (ok bool) {
 	if iter.Valid() {
 		highSlot, _ = blockstore.ParseSlotKey(iter.Key().Data())
 	}
-	fmt.Println("slot_meta_range:")
-	fmt.Println("  first:", lowSlot)
-	fmt.Println("  last:", highSlot)
+	emit("slot_meta_range", slotRangeDump{First: lowSlot, Last: highSlot})
 
 	dumpSlots(metaMap)
 	return ok
@@ -215,8 +334,6 @@ func getSlotMetas(db *blockstore.DB, slots []uint) bool {
 	if err != nil {
 		log.Println("Failed to get slot metas:", err)
 	}
-	fmt.Println("slot_meta")
-
 	metaMap := make(map[uint64]*blockstore.SlotMeta)
 	for i, meta := range metas {
 		metaMap[slots64[i]] = meta
@@ -225,13 +342,49 @@ func getSlotMetas(db *blockstore.DB, slots []uint) bool {
 	return true
 }
 
+// slotMetaDump is the YAML representation of a blockstore.SlotMeta.
+//
+// Unknown LastIndex and ParentSlot values render as null.
+type slotMetaDump struct {
+	Slot                 uint64   `yaml:"slot" json:"slot"`
+	Consumed             uint64   `yaml:"consumed" json:"consumed"`
+	Received             uint64   `yaml:"received" json:"received"`
+	FirstShredTimestamp  uint64   `yaml:"first_shred_timestamp" json:"first_shred_timestamp"`
+	LastIndex            *uint64  `yaml:"last_index" json:"last_index"`
+	ParentSlot           *uint64  `yaml:"parent_slot" json:"parent_slot"`
+	NextSlots            []uint64 `yaml:"next_slots" json:"next_slots"`
+	IsConnected          bool     `yaml:"is_connected" json:"is_connected"`
+	CompletedDataIndexes []uint32 `yaml:"completed_data_indexes" json:"completed_data_indexes"`
+}
+
+func newSlotMetaDump(meta *blockstore.SlotMeta) *slotMetaDump {
+	if meta == nil {
+		return nil
+	}
+	return &slotMetaDump{
+		Slot:                 meta.Slot,
+		Consumed:             meta.Consumed,
+		Received:             meta.Received,
+		FirstShredTimestamp:  meta.FirstShredTimestamp,
+		LastIndex:            meta.LastIndexOpt(),
+		ParentSlot:           meta.ParentSlotOpt(),
+		NextSlots:            meta.NextSlots,
+		IsConnected:          meta.IsConnected,
+		CompletedDataIndexes: meta.CompletedDataIndexes,
+	}
+}
+
 func dumpSlots(metaMap map[uint64]*blockstore.SlotMeta) {
-	fmt.Println("slots:")
-	enc := yaml.NewEncoder(textio.NewPrefixWriter(os.Stdout, "  "))
-	enc.SetIndent(2)
-	if err := enc.Encode(metaMap); err != nil {
-		panic(err.Error())
+	dumps := make(map[uint64]*slotMetaDump, len(metaMap))
+	for slot, meta := range metaMap {
+		dumps[slot] = newSlotMetaDump(meta)
 	}
+	emit("slots", dumps)
+}
+
+type slotRangeDump struct {
+	First uint64 `yaml:"first" json:"first"`
+	Last  uint64 `yaml:"last" json:"last"`
 }
 
 func getBlock(db *blockstore.DB, slot uint64) bool {
@@ -241,19 +394,58 @@ func getBlock(db *blockstore.DB, slot uint64) bool {
 		return false
 	}
 
-	// super ugly but whatever
-	// Need this hack to have instruction data ([]byte) serialized as base64, not a massive byte-by-byte list
-	blockStr := jsonStr(block)
-	var x any
-	_ = json.Unmarshal([]byte(blockStr), &x)
-	fmt.Println("blocks:")
-	fmt.Printf("  %d:\n", slot)
-	enc := yaml.NewEncoder(textio.NewPrefixWriter(os.Stdout, "    "))
-	enc.SetIndent(2)
-	enc.Encode(x)
+	emit("blocks", map[uint64]any{slot: jsonValue(block)})
 	return true
 }
 
+// exportBlocks writes the rooted blocks in a slot range as newline-delimited JSON,
+// one object with the slot and the block per line.
+func exportBlocks(db *blockstore.DB, rangeStr string, outPath string) bool {
+	first, last, ok := parseSlotRange(rangeStr)
+	if !ok {
+		log.Print("Invalid slot range: ", rangeStr)
+		return false
+	}
+
+	out := os.Stdout
+	if outPath != "-" {
+		f, err := os.Create(outPath)
+		if err != nil {
+			log.Print("Failed to create output file: ", err)
+			return false
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks, err := db.StreamBlocks(ctx, first, last)
+	if err != nil {
+		log.Print("Failed to stream blocks: ", err)
+		return false
+	}
+	for res := range blocks {
+		if res.Err != nil {
+			log.Printf("Failed to get block %d: %s", res.Slot, res.Err)
+			ok = false
+			continue
+		}
+		line := map[string]any{"slot": res.Slot, "block": res.Block}
+		if err := enc.Encode(line); err != nil {
+			log.Print("Failed to write block: ", err)
+			return false
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Print("Failed to write blocks: ", err)
+		return false
+	}
+	return ok
+}
+
 func getShreds(db *blockstore.DB, shredStr string, coding bool) bool {
 	slot, index, ok := parseShredIndex(shredStr)
 	if !ok {
@@ -261,22 +453,20 @@ func getShreds(db *blockstore.DB, shredStr string, coding bool) bool {
 		return false
 	}
 
-	var shred *grocksdb.Slice
+	var shred []byte
 	var err error
 	if coding {
-		shred, err = db.GetCodingShred(slot, index)
+		shred, err = db.GetCodingShredBytes(slot, index)
 	} else {
-		shred, err = db.GetDataShred(slot, index)
+		shred, err = db.GetDataShredBytes(slot, index)
 	}
-	if err != nil {
-		log.Printf("Can't get shred %s: %s", shredStr, err)
-		return false
-	}
-	if !shred.Exists() {
+	if errors.Is(err, blockstore.ErrNotFound) {
 		log.Println("No such shred:", shredStr)
 		return false
+	} else if err != nil {
+		log.Printf("Can't get shred %s: %s", shredStr, err)
+		return false
 	}
-	defer shred.Free()
 
 	var shredType string
 	if coding {
@@ -285,18 +475,159 @@ func getShreds(db *blockstore.DB, shredStr string, coding bool) bool {
 		shredType = "data_shred"
 	}
 
-	fmt.Printf(`%s:
-  %s: |
-    %s
-`,
-		shredType,
-		jsonStr(shredStr),
-		base64.StdEncoding.EncodeToString(shred.Data()))
+	emit(shredType, map[string]string{
+		shredStr: base64.StdEncoding.EncodeToString(shred),
+	})
+	return true
+}
+
+// txStatusDump is the YAML representation of a transaction status.
+type txStatusDump struct {
+	Slot         uint64   `yaml:"slot" json:"slot"`
+	Err          *string  `yaml:"err" json:"err"` // hex-encoded bincode TransactionError
+	Fee          uint64   `yaml:"fee" json:"fee"`
+	PreBalances  []uint64 `yaml:"pre_balances" json:"pre_balances"`
+	PostBalances []uint64 `yaml:"post_balances" json:"post_balances"`
+	LogMessages  []string `yaml:"log_messages" json:"log_messages"`
+}
+
+func getTxStatus(db *blockstore.DB, sigStr string) bool {
+	sig, err := solana.SignatureFromBase58(sigStr)
+	if err != nil {
+		log.Printf("Invalid signature %s: %s", sigStr, err)
+		return false
+	}
+	tx, err := db.GetTransaction(sig)
+	if err != nil {
+		log.Printf("Failed to get transaction %s: %s", sig, err)
+		return false
+	}
+
+	dump := txStatusDump{
+		Slot:         tx.Slot,
+		Fee:          tx.Meta.Fee,
+		PreBalances:  tx.Meta.PreBalances,
+		PostBalances: tx.Meta.PostBalances,
+		LogMessages:  tx.Meta.LogMessages,
+	}
+	if tx.Meta.Err != nil {
+		errStr := hex.EncodeToString(tx.Meta.Err)
+		dump.Err = &errStr
+	}
+	emit("transaction_statuses", map[string]*txStatusDump{sig.String(): &dump})
+	return true
+}
+
+func getSignaturesForAddress(db *blockstore.DB, addrStr string, limit int) bool {
+	if limit < 0 {
+		log.Printf("Invalid limit %d", limit)
+		return false
+	}
+	addr, err := solana.PublicKeyFromBase58(addrStr)
+	if err != nil {
+		log.Printf("Invalid address %s: %s", addrStr, err)
+		return false
+	}
+	sigs, err := db.GetSignaturesForAddress(addr, limit)
+	if errors.Is(err, blockstore.ErrColumnFamilyUnavailable) {
+		log.Print("Ledger does not index transactions by address")
+		return false
+	} else if err != nil {
+		log.Printf("Failed to get signatures for %s: %s", addr, err)
+		return false
+	}
 
+	emit("address_signatures", map[string][]blockstore.AddressSignature{addr.String(): sigs})
 	return true
 }
 
-func jsonStr(v any) string {
-	buf, _ := json.Marshal(v)
-	return string(buf)
+// jsonValue converts v to its generic JSON representation,
+// making the YAML output honor JSON marshalers such as blockstore.Block's.
+func jsonValue(v any) any {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		panic(err.Error())
+	}
+	var x any
+	_ = json.Unmarshal(buf, &x)
+	return x
+}
+
+// verifyDump is the YAML representation of the result of --verify.
+type verifyDump struct {
+	Checked     uint64            `yaml:"checked" json:"checked"`
+	Passed      uint64            `yaml:"passed" json:"passed"`
+	Failed      uint64            `yaml:"failed" json:"failed"`
+	PoHSkipped  uint64            `yaml:"poh_skipped" json:"poh_skipped"` // parent slot unavailable
+	FailedSlots map[uint64]string `yaml:"failed_slots" json:"failed_slots"`
+}
+
+// verifySlotRange checks the shreds, block and PoH chain of each rooted full slot in a range.
+func verifySlotRange(db *blockstore.DB, rangeStr string) bool {
+	first, last, ok := parseSlotRange(rangeStr)
+	if !ok {
+		log.Print("Invalid slot range: ", rangeStr)
+		return false
+	}
+
+	var slots []uint64
+	iter := db.BoundedIterSlotMetas(first, last)
+	for ; iter.Valid(); iter.Next() {
+		slot, meta, err := iter.SlotMeta()
+		if err != nil {
+			log.Printf("While ranging slot metas (%x): %s", iter.Key().Data(), err)
+			ok = false
+			continue
+		}
+		if meta.IsFull() {
+			slots = append(slots, slot)
+		}
+	}
+	iter.Close()
+
+	dump := verifyDump{FailedSlots: make(map[uint64]string)}
+	for _, slot := range slots {
+		isRoot, err := db.IsRoot(slot)
+		if err != nil {
+			log.Printf("Failed to check root %d: %s", slot, err)
+			ok = false
+			continue
+		}
+		if !isRoot {
+			continue
+		}
+		dump.Checked++
+		pohSkipped, err := verifySlot(db, slot)
+		if pohSkipped {
+			dump.PoHSkipped++
+		}
+		if err != nil {
+			dump.Failed++
+			dump.FailedSlots[slot] = err.Error()
+			continue
+		}
+		dump.Passed++
+	}
+
+	emit("verify", dump)
+	return ok && dump.Failed == 0
+}
+
+func verifySlot(db *blockstore.DB, slot uint64) (pohSkipped bool, err error) {
+	v, err := db.VerifySlotMeta(slot)
+	if err != nil {
+		return false, fmt.Errorf("cannot verify slot meta: %w", err)
+	}
+	if !v.Consistent() {
+		return false, fmt.Errorf("slot meta inconsistent with data shreds: %d missing, %d extra, %d invalid completed indexes",
+			len(v.MissingShreds), len(v.ExtraShreds), len(v.InvalidCompletedIndexes))
+	}
+	if _, err := db.GetBlock(slot); err != nil {
+		return false, fmt.Errorf("cannot get block: %w", err)
+	}
+	err = db.VerifySlotPoH(slot)
+	if errors.Is(err, blockstore.ErrNotFound) {
+		return true, nil
+	}
+	return false, err
 }
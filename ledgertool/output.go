@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/segmentio/textio"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+var outputFormat = formatYAML
+
+// emit dumps a top-level value under the given key.
+//
+// In YAML mode, all values form a single document.
+// In JSON mode, each value is written as a separate object on its own line.
+func emit(key string, v any) {
+	switch outputFormat {
+	case formatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		if err := enc.Encode(map[string]any{key: v}); err != nil {
+			panic(err.Error())
+		}
+	default:
+		fmt.Printf("%s:\n", key)
+		enc := yaml.NewEncoder(textio.NewPrefixWriter(os.Stdout, "  "))
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			panic(err.Error())
+		}
+	}
+}
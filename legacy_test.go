@@ -0,0 +1,100 @@
+package blockstore_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+func testTransaction(sig byte) blockstore.Transaction {
+	return blockstore.Transaction{
+		Transaction: solana.Transaction{
+			Signatures: []solana.Signature{{sig}},
+			Message: solana.Message{
+				AccountKeys: []solana.PublicKey{{2}, {3}},
+				Header: solana.MessageHeader{
+					NumRequiredSignatures:       1,
+					NumReadonlyUnsignedAccounts: 1,
+				},
+				RecentBlockhash: solana.Hash{4},
+				Instructions: []solana.CompiledInstruction{
+					{ProgramIDIndex: 1, Accounts: []uint16{0}, Data: []byte{5, 6}},
+				},
+			},
+		},
+	}
+}
+
+// TestGetBlockLegacy reads a block of legacy data shreds spanning multiple shreds,
+// stored both zero-padded and trimmed to their size like older validators did.
+func TestGetBlockLegacy(t *testing.T) {
+	// Ticks are 48 bytes each, filling a few shreds.
+	var entries []blockstore.Entry
+	for i := 0; i < 64; i++ {
+		entries = append(entries, blockstore.Entry{NumHashes: 1, Hash: solana.Hash{byte(i)}})
+	}
+	entries = append(entries, blockstore.Entry{
+		NumHashes:    1,
+		Hash:         solana.Hash{0xff},
+		Transactions: []blockstore.Transaction{testTransaction(1), testTransaction(2)},
+	})
+
+	b := testutil.NewLedger(t)
+	b.AddBlock(99, 98, []blockstore.Entry{{NumHashes: 1, Hash: solana.Hash{0xee}}})
+	shreds := testutil.LegacyDataShreds(100, 99, testutil.EncodeEntries(t, entries))
+	if len(shreds) < 3 {
+		t.Fatalf("fixture spans %d shreds", len(shreds))
+	}
+	for i, payload := range shreds {
+		// The last shred is partially filled, store it trimmed.
+		if i%2 == 1 || i == len(shreds)-1 {
+			payload = testutil.TrimLegacyPadding(payload)
+		}
+		b.AddShred(100, uint64(i), payload)
+	}
+	b.AddSlot(testutil.FullSlotMeta(100, 99, len(shreds)))
+
+	db, err := blockstore.OpenReadOnly(b.Path())
+	if err != nil {
+		t.Fatalf("cannot open ledger: %s", err)
+	}
+	defer db.Close()
+
+	block, err := db.GetBlock(100)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	if block.BlockHash != (solana.Hash{0xff}) {
+		t.Errorf("block hash %s", block.BlockHash)
+	}
+	if block.PreviousBlockHash != (solana.Hash{0xee}) {
+		t.Errorf("previous block hash %s", block.PreviousBlockHash)
+	}
+	if block.ParentSlot != 99 {
+		t.Errorf("parent slot %d", block.ParentSlot)
+	}
+	if len(block.Transactions) != 2 {
+		t.Fatalf("got %d transactions", len(block.Transactions))
+	}
+	for i, tx := range block.Transactions {
+		if len(tx.Signatures) != 1 || tx.Signatures[0] != (solana.Signature{byte(i + 1)}) {
+			t.Errorf("transaction %d: signatures %v", i, tx.Signatures)
+		}
+		if tx.Message.RecentBlockhash != (solana.Hash{4}) {
+			t.Errorf("transaction %d: recent blockhash %s", i, tx.Message.RecentBlockhash)
+		}
+		if ins := tx.Message.Instructions; len(ins) != 1 || string(ins[0].Data) != "\x05\x06" {
+			t.Errorf("transaction %d: instructions %+v", i, ins)
+		}
+	}
+
+	entriesGot, err := db.GetSlotEntriesComplete(100)
+	if err != nil {
+		t.Fatalf("GetSlotEntriesComplete: %s", err)
+	}
+	if len(entriesGot) != len(entries) {
+		t.Errorf("got %d entries, want %d", len(entriesGot), len(entries))
+	}
+}
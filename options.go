@@ -0,0 +1,333 @@
+package blockstore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/linxGnu/grocksdb"
+)
+
+// OpenOptions tunes the RocksDB client.
+//
+// The zero value matches the behavior of OpenReadOnly.
+type OpenOptions struct {
+	// BlockCacheSize is the capacity in bytes of an LRU block cache
+	// shared by all column families.
+	// Zero keeps RocksDB's default per-column family cache.
+	BlockCacheSize uint64
+
+	// ShredBloomFilters enables bloom filters on CfDataShred and CfCodeShred,
+	// speeding up point lookups of shreds.
+	ShredBloomFilters bool
+
+	// ReadAheadSize sets the read-ahead in bytes of iterators created
+	// by this package without explicit read options.
+	// Zero keeps RocksDB's default.
+	ReadAheadSize uint64
+
+	// MaxOpenFiles limits the number of files RocksDB keeps open.
+	// Zero keeps RocksDB's default (unlimited).
+	MaxOpenFiles int
+
+	// ParanoidChecks makes RocksDB aggressively check data integrity.
+	ParanoidChecks bool
+
+	// RequireFlushed makes OpenReadOnly fail if the ledger has a write-ahead log,
+	// which may hold recent writes not yet flushed to table files.
+	// Has no effect on OpenSecondary.
+	RequireFlushed bool
+
+	// Immutable avoids work RocksDB performs when opening a database that may change,
+	// such as updating statistics and checking file sizes, and keeps all files open.
+	// Enables best-effort recovery and moves the info log out of the ledger directory.
+	// Set by OpenArchive.
+	Immutable bool
+
+	// RateLimit throttles the bytes per second of values read by methods of DB and its iterators,
+	// e.g. to keep long scans from saturating disk I/O.
+	// Keys are not charged, so scans over keys alone are not throttled,
+	// and neither are reads through the underlying RocksDB handles or iterators' raw Value.
+	// Zero disables throttling.
+	RateLimit int64
+
+	// Env replaces the RocksDB environment, e.g. to customize thread pools or file access.
+	// Nil keeps RocksDB's default environment.
+	// The Env must remain valid until the DB is closed and must not be shared between DBs.
+	Env *grocksdb.Env
+
+	// Logger receives diagnostic messages of this package.
+	// Nil discards them.
+	Logger Logger
+
+	// Tracer receives spans of methods taking a context.
+	// Nil disables tracing.
+	Tracer Tracer
+
+	// SlotMetaCacheSize is the number of slot metas kept in an in-memory LRU cache.
+	// The cache is cleared by DB.TryCatchUpWithPrimary.
+	// Zero disables the cache.
+	SlotMetaCacheSize int
+}
+
+// Logger is implemented by *log.Logger and similar.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Printf(string, ...any) {}
+
+// Option configures OpenReadOnly or OpenSecondary.
+type Option func(*OpenOptions)
+
+// WithBlockCache sets up an LRU block cache of the given size in bytes,
+// shared by all column families.
+func WithBlockCache(size uint64) Option {
+	return func(o *OpenOptions) {
+		o.BlockCacheSize = size
+	}
+}
+
+// WithShredBloomFilters enables bloom filters on the shred column families.
+func WithShredBloomFilters() Option {
+	return func(o *OpenOptions) {
+		o.ShredBloomFilters = true
+	}
+}
+
+// WithReadAhead sets the read-ahead in bytes of iterators created by this package,
+// reducing random I/O of sequential scans.
+// Point lookups are unaffected.
+func WithReadAhead(bytes int) Option {
+	return func(o *OpenOptions) {
+		if bytes > 0 {
+			o.ReadAheadSize = uint64(bytes)
+		}
+	}
+}
+
+// WithMaxOpenFiles limits the number of files RocksDB keeps open.
+func WithMaxOpenFiles(n int) Option {
+	return func(o *OpenOptions) {
+		o.MaxOpenFiles = n
+	}
+}
+
+// WithParanoidChecks makes RocksDB aggressively check data integrity.
+func WithParanoidChecks(enabled bool) Option {
+	return func(o *OpenOptions) {
+		o.ParanoidChecks = enabled
+	}
+}
+
+// WithRequireFlushed makes OpenReadOnly fail if the ledger has unflushed writes.
+func WithRequireFlushed(enabled bool) Option {
+	return func(o *OpenOptions) {
+		o.RequireFlushed = enabled
+	}
+}
+
+// WithRateLimiter throttles values read by the DB to the given number of bytes per second.
+// See OpenOptions.RateLimit.
+func WithRateLimiter(bytesPerSec int64) Option {
+	return func(o *OpenOptions) {
+		o.RateLimit = bytesPerSec
+	}
+}
+
+// WithEnv replaces the RocksDB environment.
+func WithEnv(env *grocksdb.Env) Option {
+	return func(o *OpenOptions) {
+		o.Env = env
+	}
+}
+
+// WithLogger redirects diagnostic messages of this package.
+func WithLogger(logger Logger) Option {
+	return func(o *OpenOptions) {
+		o.Logger = logger
+	}
+}
+
+// WithSlotMetaCache caches up to n slot metas in memory.
+func WithSlotMetaCache(n int) Option {
+	return func(o *OpenOptions) {
+		o.SlotMetaCacheSize = n
+	}
+}
+
+// WithTracer traces methods taking a context.
+func WithTracer(tracer Tracer) Option {
+	return func(o *OpenOptions) {
+		o.Tracer = tracer
+	}
+}
+
+func collectOptions(options []Option) (o OpenOptions) {
+	for _, option := range options {
+		option(&o)
+	}
+	return
+}
+
+// Bits per key used for shred bloom filters.
+const shredBloomFilterBits = 10
+
+// OpenReadOnlyWithOptions is like OpenReadOnly but with custom options.
+func OpenReadOnlyWithOptions(path string, o OpenOptions) (*DB, error) {
+	opts, cfNames, cfOpts, err := getOpts(path, &o)
+	if err != nil {
+		return nil, err
+	}
+
+	rawDB, cfHandles, err := grocksdb.OpenDbForReadOnlyColumnFamilies(
+		opts,
+		path,
+		cfNames,
+		cfOpts,
+		/*errorIfWalFileExists*/ o.RequireFlushed,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDB(rawDB, cfNames, cfHandles, &o)
+}
+
+// OpenArchive attaches to a blockstore that is never written to again,
+// e.g. a ledger copied to read-only storage.
+//
+// Like OpenReadOnly, but skips RocksDB work that assumes the files may change,
+// recovers on a best-effort basis from missing or corrupt files,
+// and writes the RocksDB info log to the system temporary directory instead of the ledger directory.
+func OpenArchive(path string, options ...Option) (*DB, error) {
+	o := collectOptions(options)
+	o.Immutable = true
+	return OpenReadOnlyWithOptions(path, o)
+}
+
+// dbOptions returns the RocksDB options of the database.
+func (o *OpenOptions) dbOptions() (*grocksdb.Options, error) {
+	opts := grocksdb.NewDefaultOptions()
+	if o.MaxOpenFiles != 0 {
+		opts.SetMaxOpenFiles(o.MaxOpenFiles)
+	}
+	if o.ParanoidChecks {
+		opts.SetParanoidChecks(true)
+	}
+	if o.Env != nil {
+		opts.SetEnv(o.Env)
+	}
+	if o.Immutable {
+		if o.MaxOpenFiles == 0 {
+			opts.SetMaxOpenFiles(-1)
+		}
+		opts.SetSkipStatsUpdateOnDBOpen(true)
+		opts.SetSkipCheckingSSTFileSizesOnDBOpen(true)
+		opts.SetInfoLogLevel(grocksdb.FatalInfoLogLevel)
+		opts.SetDbLogDir(os.TempDir())
+		opts.SetKeepLogFileNum(1)
+		// grocksdb has no setter for best_efforts_recovery.
+		recovering, err := grocksdb.GetOptionsFromString(opts, "best_efforts_recovery=true")
+		opts.Destroy()
+		if err != nil {
+			return nil, fmt.Errorf("cannot enable best-effort recovery: %w", err)
+		}
+		opts = recovering
+	}
+	return opts, nil
+}
+
+func (o *OpenOptions) logger() Logger {
+	if o.Logger == nil {
+		return noopLogger{}
+	}
+	return o.Logger
+}
+
+func (o *OpenOptions) tracer() Tracer {
+	if o.Tracer == nil {
+		return noopTracer{}
+	}
+	return o.Tracer
+}
+
+// cfOptions returns the RocksDB options of a column family.
+func (o *OpenOptions) cfOptions(name string, cache *grocksdb.Cache) *grocksdb.Options {
+	opts := grocksdb.NewDefaultOptions()
+	bloom := o.ShredBloomFilters && (name == CfDataShred || name == CfCodeShred)
+	if cache == nil && !bloom {
+		return opts
+	}
+	table := grocksdb.NewDefaultBlockBasedTableOptions()
+	if cache != nil {
+		table.SetBlockCache(cache)
+	}
+	if bloom {
+		table.SetFilterPolicy(grocksdb.NewBloomFilter(shredBloomFilterBits))
+	}
+	opts.SetBlockBasedTableFactory(table)
+	return opts
+}
+
+// IterOptions tunes an iterator.
+//
+// Nil options select the defaults of the DB.
+// Start from DefaultIterOptions to change individual fields,
+// as the zero value does not fill the block cache.
+type IterOptions struct {
+	// FillCache adds blocks read by the iterator to the block cache.
+	// Disable for bulk scans to avoid evicting hot blocks.
+	FillCache bool
+
+	// LowerBound is the smallest key the iterator can reach, inclusive.
+	// Nil means unbounded.
+	LowerBound []byte
+
+	// UpperBound is the key at which the iterator stops, exclusive.
+	// Nil means unbounded.
+	UpperBound []byte
+
+	// Tailing creates an iterator that observes data added after its creation.
+	Tailing bool
+}
+
+// DefaultIterOptions returns the options used for nil IterOptions.
+func DefaultIterOptions() *IterOptions {
+	return &IterOptions{FillCache: true}
+}
+
+// newUserIterReadOptions translates IterOptions to RocksDB read options.
+//
+// Returns the shared default options of the DB if o is nil.
+// Otherwise, the caller owns the returned options.
+func (d *DB) newUserIterReadOptions(o *IterOptions) *grocksdb.ReadOptions {
+	if o == nil {
+		return d.iterOpts
+	}
+	opts := d.newIterReadOptions()
+	opts.SetFillCache(o.FillCache)
+	if o.LowerBound != nil {
+		opts.SetIterateLowerBound(o.LowerBound)
+	}
+	if o.UpperBound != nil {
+		opts.SetIterateUpperBound(o.UpperBound)
+	}
+	if o.Tailing {
+		opts.SetTailing(true)
+	}
+	return opts
+}
+
+// newIterReadOptions returns the read options of iterators created without explicit options.
+func (d *DB) newIterReadOptions() *grocksdb.ReadOptions {
+	opts := grocksdb.NewDefaultReadOptions()
+	if d.readAheadSize > 0 {
+		opts.SetReadaheadSize(d.readAheadSize)
+	}
+	if d.snapshot != nil {
+		opts.SetSnapshot(d.snapshot)
+	}
+	return opts
+}
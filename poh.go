@@ -0,0 +1,105 @@
+package blockstore
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ErrPoHMismatch is returned when an entry hash does not follow from the Proof of History chain.
+var ErrPoHMismatch = errors.New("proof of history mismatch")
+
+// VerifyEntries checks the Proof of History hash chain of a list of entries,
+// starting at the hash of the last entry of the parent slot.
+//
+// Returns an error wrapping ErrPoHMismatch for the first entry with an invalid hash.
+func VerifyEntries(start solana.Hash, entries []Entry) error {
+	hash := start
+	for i := range entries {
+		next := nextEntryHash(hash, &entries[i])
+		if next != entries[i].Hash {
+			return fmt.Errorf("%w: entry %d has hash %s, expected %s", ErrPoHMismatch, i, entries[i].Hash, next)
+		}
+		hash = next
+	}
+	return nil
+}
+
+// VerifySlotPoH checks the Proof of History hash chain of a full slot.
+//
+// Returns ErrNotFound if the slot or the last entry hash of its parent is unavailable.
+func (d *DB) VerifySlotPoH(slot uint64) error {
+	entries, err := d.GetSlotEntriesComplete(slot)
+	if err != nil {
+		return err
+	}
+	meta, err := d.GetSlotMeta(slot)
+	if err != nil {
+		return err
+	}
+	parent := meta.ParentSlotOpt()
+	if parent == nil || *parent == slot {
+		return ErrNotFound
+	}
+	start, err := d.getLastEntryHash(*parent)
+	if err != nil {
+		return err
+	}
+	if err := VerifyEntries(start, entries); err != nil {
+		return fmt.Errorf("slot %d: %w", slot, err)
+	}
+	return nil
+}
+
+// nextEntryHash computes the hash of an entry from the hash of the previous entry.
+//
+// Matches solana_entry::entry::next_hash of the validator.
+func nextEntryHash(prev solana.Hash, entry *Entry) solana.Hash {
+	if entry.NumHashes == 0 && len(entry.Transactions) == 0 {
+		return prev
+	}
+	hash := [32]byte(prev)
+	for n := uint64(1); n < entry.NumHashes; n++ {
+		hash = sha256.Sum256(hash[:])
+	}
+	if len(entry.Transactions) == 0 {
+		return sha256.Sum256(hash[:])
+	}
+	mixin := hashTransactions(entry.Transactions)
+	return sha256.Sum256(append(hash[:], mixin[:]...))
+}
+
+// hashTransactions returns the Merkle root over the signatures of a list of transactions.
+//
+// Matches solana_entry::entry::hash_transactions of the validator:
+// leaves and inner nodes are prefixed with 0 and 1 respectively,
+// and the last node of a level with an odd length is paired with itself.
+func hashTransactions(txns []Transaction) [32]byte {
+	var level [][32]byte
+	for i := range txns {
+		for _, sig := range txns[i].Signatures {
+			level = append(level, sha256.Sum256(append([]byte{0}, sig[:]...)))
+		}
+	}
+	if len(level) == 0 {
+		return [32]byte{}
+	}
+	buf := make([]byte, 1+2*32)
+	buf[0] = 1
+	for len(level) > 1 {
+		next := level[:0]
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			copy(buf[1:33], level[i][:])
+			copy(buf[33:], right[:])
+			next = append(next, sha256.Sum256(buf))
+		}
+		level = next
+	}
+	return level[0]
+}
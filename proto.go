@@ -0,0 +1,290 @@
+package blockstore
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gagliardetto/solana-go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Solana stores some column values (e.g. CfTransactionStatus) as protobuf.
+// The schemas are defined in the solana-storage-proto crate:
+// https://github.com/solana-labs/solana/tree/master/storage-proto/proto
+//
+// The messages are decoded by hand to avoid pulling in generated code.
+
+type protoField struct {
+	num   protowire.Number
+	typ   protowire.Type
+	u64   uint64 // varint, fixed32, fixed64
+	bytes []byte // length-delimited
+}
+
+// walkProto calls fn for each field in the serialized protobuf message b.
+func walkProto(b []byte, fn func(f *protoField) error) error {
+	for len(b) > 0 {
+		var f protoField
+		var n int
+		f.num, f.typ, n = protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch f.typ {
+		case protowire.VarintType:
+			f.u64, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			f.u64 = uint64(v)
+		case protowire.Fixed64Type:
+			f.u64, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(f.num, f.typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(&f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendUint64s decodes a packed or unpacked repeated uint64 field.
+func (f *protoField) appendUint64s(list []uint64) ([]uint64, error) {
+	if f.typ != protowire.BytesType {
+		return append(list, f.u64), nil
+	}
+	b := f.bytes
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		list = append(list, v)
+		b = b[n:]
+	}
+	return list, nil
+}
+
+// decodeTransactionStatusMeta decodes a solana.storage.ConfirmedBlock.TransactionStatusMeta.
+func decodeTransactionStatusMeta(b []byte) (*TransactionStatusMeta, error) {
+	meta := new(TransactionStatusMeta)
+	innerInstructionsNone := false
+	logMessagesNone := false
+	err := walkProto(b, func(f *protoField) (err error) {
+		switch f.num {
+		case 1: // err
+			err = walkProto(f.bytes, func(f *protoField) error {
+				if f.num == 1 {
+					meta.Err = append([]byte{}, f.bytes...)
+				}
+				return nil
+			})
+		case 2: // fee
+			meta.Fee = f.u64
+		case 3: // pre_balances
+			meta.PreBalances, err = f.appendUint64s(meta.PreBalances)
+		case 4: // post_balances
+			meta.PostBalances, err = f.appendUint64s(meta.PostBalances)
+		case 5: // inner_instructions
+			var inner *InnerInstructions
+			if inner, err = decodeInnerInstructions(f.bytes); err == nil {
+				meta.InnerInstructions = append(meta.InnerInstructions, *inner)
+			}
+		case 6: // log_messages
+			meta.LogMessages = append(meta.LogMessages, string(f.bytes))
+		case 7: // pre_token_balances
+			var balance *TokenBalance
+			if balance, err = decodeTokenBalance(f.bytes); err == nil {
+				meta.PreTokenBalances = append(meta.PreTokenBalances, *balance)
+			}
+		case 8: // post_token_balances
+			var balance *TokenBalance
+			if balance, err = decodeTokenBalance(f.bytes); err == nil {
+				meta.PostTokenBalances = append(meta.PostTokenBalances, *balance)
+			}
+		case 9: // rewards
+			var reward *Reward
+			if reward, err = decodeReward(f.bytes); err == nil {
+				meta.Rewards = append(meta.Rewards, *reward)
+			}
+		case 10: // inner_instructions_none
+			innerInstructionsNone = f.u64 != 0
+		case 11: // log_messages_none
+			logMessagesNone = f.u64 != 0
+		case 12: // loaded_writable_addresses
+			meta.LoadedAddresses.Writable = append(meta.LoadedAddresses.Writable, solana.PublicKeyFromBytes(f.bytes))
+		case 13: // loaded_readonly_addresses
+			meta.LoadedAddresses.Readonly = append(meta.LoadedAddresses.Readonly, solana.PublicKeyFromBytes(f.bytes))
+		case 14: // return_data
+			meta.ReturnData, err = decodeReturnData(f.bytes)
+		case 16: // compute_units_consumed
+			units := f.u64
+			meta.ComputeUnitsConsumed = &units
+		}
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !innerInstructionsNone && meta.InnerInstructions == nil {
+		meta.InnerInstructions = []InnerInstructions{}
+	}
+	if !logMessagesNone && meta.LogMessages == nil {
+		meta.LogMessages = []string{}
+	}
+	return meta, nil
+}
+
+// decodeInnerInstructions decodes a solana.storage.ConfirmedBlock.InnerInstructions.
+func decodeInnerInstructions(b []byte) (*InnerInstructions, error) {
+	inner := new(InnerInstructions)
+	err := walkProto(b, func(f *protoField) error {
+		switch f.num {
+		case 1: // index
+			inner.Index = uint8(f.u64)
+		case 2: // instructions
+			ins := InnerInstruction{}
+			err := walkProto(f.bytes, func(f *protoField) error {
+				switch f.num {
+				case 1: // program_id_index
+					ins.ProgramIDIndex = uint16(f.u64)
+				case 2: // accounts
+					ins.Accounts = append([]byte{}, f.bytes...)
+				case 3: // data
+					ins.Data = append([]byte{}, f.bytes...)
+				case 4: // stack_height
+					height := uint32(f.u64)
+					ins.StackHeight = &height
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			inner.Instructions = append(inner.Instructions, ins)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inner, nil
+}
+
+// decodeTokenBalance decodes a solana.storage.ConfirmedBlock.TokenBalance.
+func decodeTokenBalance(b []byte) (*TokenBalance, error) {
+	balance := new(TokenBalance)
+	err := walkProto(b, func(f *protoField) (err error) {
+		switch f.num {
+		case 1: // account_index
+			balance.AccountIndex = uint8(f.u64)
+		case 2: // mint
+			balance.Mint, err = parseOptionalPublicKey(f.bytes)
+		case 3: // ui_token_amount
+			err = walkProto(f.bytes, func(f *protoField) error {
+				amount := &balance.UiTokenAmount
+				switch f.num {
+				case 1: // ui_amount
+					amount.UiAmount = math.Float64frombits(f.u64)
+				case 2: // decimals
+					amount.Decimals = uint8(f.u64)
+				case 3: // amount
+					amount.Amount = string(f.bytes)
+				case 4: // ui_amount_string
+					amount.UiAmountString = string(f.bytes)
+				}
+				return nil
+			})
+		case 4: // owner
+			balance.Owner, err = parseOptionalPublicKey(f.bytes)
+		case 5: // program_id
+			balance.ProgramID, err = parseOptionalPublicKey(f.bytes)
+		}
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// decodeReturnData decodes a solana.storage.ConfirmedBlock.ReturnData.
+func decodeReturnData(b []byte) (*ReturnData, error) {
+	data := new(ReturnData)
+	err := walkProto(b, func(f *protoField) error {
+		switch f.num {
+		case 1: // program_id
+			data.ProgramID = solana.PublicKeyFromBytes(f.bytes)
+		case 2: // data
+			data.Data = append([]byte{}, f.bytes...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// parseOptionalPublicKey parses a base58 public key, returning the zero key for an empty string.
+func parseOptionalPublicKey(b []byte) (solana.PublicKey, error) {
+	if len(b) == 0 {
+		return solana.PublicKey{}, nil
+	}
+	return solana.PublicKeyFromBase58(string(b))
+}
+
+// decodeRewards decodes a solana.storage.ConfirmedBlock.Rewards.
+func decodeRewards(b []byte) ([]Reward, error) {
+	var rewards []Reward
+	err := walkProto(b, func(f *protoField) error {
+		if f.num != 1 { // rewards
+			return nil
+		}
+		reward, err := decodeReward(f.bytes)
+		if err != nil {
+			return err
+		}
+		rewards = append(rewards, *reward)
+		return nil
+	})
+	return rewards, err
+}
+
+// decodeReward decodes a solana.storage.ConfirmedBlock.Reward.
+func decodeReward(b []byte) (*Reward, error) {
+	reward := new(Reward)
+	err := walkProto(b, func(f *protoField) (err error) {
+		switch f.num {
+		case 1: // pubkey
+			reward.Pubkey, err = solana.PublicKeyFromBase58(string(f.bytes))
+		case 2: // lamports
+			reward.Lamports = int64(f.u64)
+		case 3: // post_balance
+			reward.PostBalance = f.u64
+		case 4: // reward_type
+			reward.RewardType = RewardType(f.u64)
+		case 5: // commission
+			if len(f.bytes) == 0 {
+				break
+			}
+			var commission uint64
+			commission, err = strconv.ParseUint(string(f.bytes), 10, 8)
+			c := uint8(commission)
+			reward.Commission = &c
+		}
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reward, nil
+}
@@ -0,0 +1,37 @@
+package blockstore_test
+
+import (
+	"reflect"
+	"testing"
+
+	blockstore "github.com/terorie/solana-blockstore-go"
+)
+
+func TestComputeCompletedRanges(t *testing.T) {
+	type r = blockstore.CompletedRange
+	tests := []struct {
+		name      string
+		start     uint32
+		completed []uint32
+		consumed  uint32
+		want      []blockstore.CompletedRange
+	}{
+		{name: "Empty", start: 0, completed: nil, consumed: 10, want: nil},
+		{name: "All", start: 0, completed: []uint32{3, 7}, consumed: 10, want: []r{{0, 3}, {4, 7}}},
+		{name: "ConsumedCutOff", start: 0, completed: []uint32{3, 7, 12}, consumed: 10, want: []r{{0, 3}, {4, 7}}},
+		{name: "ConsumedExclusive", start: 0, completed: []uint32{3, 7}, consumed: 7, want: []r{{0, 3}}},
+		{name: "NothingConsumed", start: 0, completed: []uint32{3, 7}, consumed: 0, want: nil},
+		{name: "StartIndex", start: 4, completed: []uint32{3, 7, 9}, consumed: 10, want: []r{{4, 7}, {8, 9}}},
+		{name: "StartAtCompleted", start: 3, completed: []uint32{3, 7}, consumed: 10, want: []r{{3, 3}, {4, 7}}},
+		{name: "StartPastCompleted", start: 8, completed: []uint32{3, 7}, consumed: 10, want: nil},
+		{name: "Adjacent", start: 0, completed: []uint32{0, 1, 2, 5}, consumed: 6, want: []r{{0, 0}, {1, 1}, {2, 2}, {3, 5}}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := blockstore.ComputeCompletedRanges(tc.start, tc.completed, tc.consumed)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
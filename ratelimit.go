@@ -0,0 +1,50 @@
+package blockstore
+
+import (
+	"sync"
+	"time"
+)
+
+// readLimiter throttles the bytes read from RocksDB to a fixed rate.
+//
+// Reads are charged after the fact: a read exceeding the available budget
+// puts the limiter into debt, which the next reads wait out.
+// A nil *readLimiter does not throttle.
+type readLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // bytes per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newReadLimiter(bytesPerSec int64) *readLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	rate := float64(bytesPerSec)
+	return &readLimiter{rate: rate, burst: rate, tokens: rate, last: time.Now()}
+}
+
+// charge accounts for n bytes read, sleeping while the limiter is in debt.
+func (l *readLimiter) charge(n int) {
+	if l == nil || n <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
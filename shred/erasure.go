@@ -0,0 +1,197 @@
+package shred
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reed-Solomon erasure coding over GF(2^8),
+// compatible with the reed-solomon-erasure crate used by the Solana validator.
+
+var ErrTooFewShards = errors.New("too few shards to recover")
+
+// Generator polynomial x^8 + x^4 + x^3 + x^2 + 1 of the Galois field.
+const gfPolynomial = 0x11D
+
+var (
+	gfExp [510]byte
+	gfLog [256]int
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfExp[i+255] = byte(x)
+		gfLog[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+gfLog[b]]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[gfLog[a]+255-gfLog[b]]
+}
+
+func gfPow(a byte, n int) byte {
+	if n == 0 {
+		return 1
+	}
+	if a == 0 {
+		return 0
+	}
+	return gfExp[(gfLog[a]*n)%255]
+}
+
+type gfMatrix [][]byte
+
+func newGFMatrix(rows, cols int) gfMatrix {
+	m := make(gfMatrix, rows)
+	for r := range m {
+		m[r] = make([]byte, cols)
+	}
+	return m
+}
+
+func (m gfMatrix) mul(o gfMatrix) gfMatrix {
+	out := newGFMatrix(len(m), len(o[0]))
+	for r := range out {
+		for c := range out[r] {
+			var v byte
+			for i := range o {
+				v ^= gfMul(m[r][i], o[i][c])
+			}
+			out[r][c] = v
+		}
+	}
+	return out
+}
+
+// invert returns the inverse of a square matrix using Gauss-Jordan elimination.
+func (m gfMatrix) invert() (gfMatrix, error) {
+	n := len(m)
+	work := newGFMatrix(n, 2*n)
+	for r := range m {
+		copy(work[r], m[r])
+		work[r][n+r] = 1
+	}
+	for c := 0; c < n; c++ {
+		if work[c][c] == 0 {
+			for r := c + 1; r < n; r++ {
+				if work[r][c] != 0 {
+					work[c], work[r] = work[r], work[c]
+					break
+				}
+			}
+		}
+		if work[c][c] == 0 {
+			return nil, errors.New("singular matrix")
+		}
+		if pivot := work[c][c]; pivot != 1 {
+			for i := range work[c] {
+				work[c][i] = gfDiv(work[c][i], pivot)
+			}
+		}
+		for r := 0; r < n; r++ {
+			if r == c || work[r][c] == 0 {
+				continue
+			}
+			factor := work[r][c]
+			for i := range work[r] {
+				work[r][i] ^= gfMul(factor, work[c][i])
+			}
+		}
+	}
+	inv := make(gfMatrix, n)
+	for r := range work {
+		inv[r] = work[r][n:]
+	}
+	return inv, nil
+}
+
+// encodingMatrix returns the systematic encoding matrix:
+// a Vandermonde matrix normalized so that its top rows are the identity.
+func encodingMatrix(numData, numTotal int) (gfMatrix, error) {
+	vandermonde := newGFMatrix(numTotal, numData)
+	for r := range vandermonde {
+		for c := range vandermonde[r] {
+			vandermonde[r][c] = gfPow(byte(r), c)
+		}
+	}
+	topInv, err := vandermonde[:numData].invert()
+	if err != nil {
+		return nil, err
+	}
+	return vandermonde.mul(topInv), nil
+}
+
+// reconstructData fills in the missing (nil) data shards
+// given any numData present shards.
+//
+// shards holds the data shards followed by the coding shards.
+func reconstructData(shards [][]byte, numData int) error {
+	if numData <= 0 || len(shards) < numData || len(shards) > 256 {
+		return fmt.Errorf("invalid erasure set with %d data and %d total shards", numData, len(shards))
+	}
+	shardSize := -1
+	var present []int
+	for i, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		if shardSize < 0 {
+			shardSize = len(shard)
+		} else if len(shard) != shardSize {
+			return fmt.Errorf("shard %d has size %d, expected %d", i, len(shard), shardSize)
+		}
+		if len(present) < numData {
+			present = append(present, i)
+		}
+	}
+	if len(present) < numData {
+		return ErrTooFewShards
+	}
+
+	encoding, err := encodingMatrix(numData, len(shards))
+	if err != nil {
+		return err
+	}
+	sub := make(gfMatrix, numData)
+	for i, row := range present {
+		sub[i] = encoding[row]
+	}
+	decoding, err := sub.invert()
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < numData; i++ {
+		if shards[i] != nil {
+			continue
+		}
+		out := make([]byte, shardSize)
+		for j, row := range present {
+			factor := decoding[i][j]
+			if factor == 0 {
+				continue
+			}
+			for k, b := range shards[row] {
+				out[k] ^= gfMul(factor, b)
+			}
+		}
+		shards[i] = out
+	}
+	return nil
+}
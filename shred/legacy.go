@@ -4,16 +4,36 @@ import bin "github.com/gagliardetto/binary"
 
 type LegacyCode struct {
 	Common  CommonHeader
+	Header  CodingHeader
 	Payload []byte
 }
 
 const (
-	LegacyHeaderSize  = 88
+	LegacyHeaderSize  = DataHeadersSize
 	LegacyPayloadSize = 1228
+
+	// Size of the erasure shard of legacy shreds.
+	LegacyErasureShardSize = LegacyPayloadSize - CodingHeadersSize
 )
 
 func LegacyCodeFromPayload(shred []byte) *LegacyCode {
-	panic("legacy shred code unimplemented")
+	code := new(LegacyCode)
+	dec := bin.NewBinDecoder(shred)
+	if err := dec.Decode(&code.Common); err != nil {
+		return nil
+	}
+	if err := dec.Decode(&code.Header); err != nil {
+		return nil
+	}
+	if code.Common.Variant != LegacyCodeID {
+		return nil
+	}
+	if len(shred) < LegacyPayloadSize {
+		return nil
+	}
+	code.Payload = make([]byte, LegacyPayloadSize)
+	copy(code.Payload, shred)
+	return code
 }
 
 func (s *LegacyCode) CommonHeader() *CommonHeader {
@@ -24,6 +44,10 @@ func (s *LegacyCode) DataHeader() *DataHeader {
 	return nil
 }
 
+func (s *LegacyCode) CodingHeader() *CodingHeader {
+	return &s.Header
+}
+
 func (s *LegacyCode) Data() ([]byte, bool) {
 	return nil, false
 }
@@ -32,12 +56,25 @@ func (s *LegacyCode) DataComplete() bool {
 	return false
 }
 
+func (s *LegacyCode) Type() ShredType {
+	return TypeLegacyCode
+}
+
+func (s *LegacyCode) IsMerkle() bool {
+	return false
+}
+
 type LegacyData struct {
 	Common  CommonHeader
 	Header  DataHeader
 	Payload []byte
 }
 
+// LegacyDataFromPayload parses a legacy data shred.
+//
+// Older ledgers store legacy data shreds with their zero padding trimmed,
+// so the payload may be shorter than LegacyPayloadSize.
+// Returns nil if the shred is malformed.
 func LegacyDataFromPayload(shred []byte) *LegacyData {
 	data := new(LegacyData)
 	dec := bin.NewBinDecoder(shred)
@@ -50,10 +87,12 @@ func LegacyDataFromPayload(shred []byte) *LegacyData {
 	if data.Common.Variant != LegacyDataID {
 		return nil
 	}
-	if len(shred) < LegacyHeaderSize {
+	if len(shred) < LegacyHeaderSize || len(shred) > LegacyPayloadSize {
+		return nil
+	}
+	if size := int(data.Header.Size); size < LegacyHeaderSize || size > LegacyPayloadSize {
 		return nil
 	}
-	// TODO Sanitize
 	data.Payload = make([]byte, LegacyPayloadSize)
 	copy(data.Payload, shred)
 	return data
@@ -67,15 +106,25 @@ func (s *LegacyData) DataHeader() *DataHeader {
 	return &s.Header
 }
 
+// Data returns the entry data carried by the shred, excluding zero padding.
 func (s *LegacyData) Data() ([]byte, bool) {
-	if int(s.Header.Size) > len(s.Payload) {
-		return nil, false // TODO more length checks
+	size := int(s.Header.Size)
+	if size < LegacyHeaderSize || size > len(s.Payload) {
+		return nil, false
 	}
-	return s.Payload[LegacyHeaderSize:], true
+	return s.Payload[LegacyHeaderSize:size], true
 }
 
 func (s *LegacyData) DataComplete() bool {
-	return s.Header.Flags&FlagDataCompleteShred == 1
+	return s.Header.Flags&FlagDataCompleteShred == FlagDataCompleteShred
+}
+
+func (s *LegacyData) Type() ShredType {
+	return TypeLegacyData
+}
+
+func (s *LegacyData) IsMerkle() bool {
+	return false
 }
 
 func (s *LegacyData) ReferenceTick() uint8 {
@@ -1,11 +1,85 @@
 package shred
 
+import (
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	// Size of the payload of Merkle data shreds.
+	MerkleDataPayloadSize = 1203
+	// Size of the payload of Merkle coding shreds.
+	MerkleCodePayloadSize = 1228
+
+	merkleProofEntrySize = 20
+	merkleRootSize       = 32
+	signatureSize        = 64
+)
+
+// merkleTrailerSize returns the size of the fields following the data or erasure shard
+// of a Merkle shred: the chained Merkle root, the Merkle proof and the retransmitter signature.
+func merkleTrailerSize(variant uint8) int {
+	size := int(variant&MerkleProofDepthMask) * merkleProofEntrySize
+	if isChained(variant) {
+		size += merkleRootSize
+	}
+	if isResigned(variant) {
+		size += signatureSize
+	}
+	return size
+}
+
+func isChained(variant uint8) bool {
+	switch variant & MerkleMask {
+	case MerkleCodeChainedID, MerkleCodeChainedResignedID, MerkleDataChainedID, MerkleDataChainedResignedID:
+		return true
+	}
+	return false
+}
+
+func isResigned(variant uint8) bool {
+	switch variant & MerkleMask {
+	case MerkleCodeChainedResignedID, MerkleDataChainedResignedID:
+		return true
+	}
+	return false
+}
+
+// retransmitterSignature returns the trailing signature of a resigned Merkle shred payload.
+func retransmitterSignature(variant uint8, payload []byte) (sig solana.Signature, ok bool) {
+	if !isResigned(variant) {
+		return sig, false
+	}
+	copy(sig[:], payload[len(payload)-signatureSize:])
+	return sig, true
+}
+
 type MerkleCode struct {
-	Common CommonHeader
+	Common  CommonHeader
+	Header  CodingHeader
+	Payload []byte
 }
 
+// MerkleCodeFromPayload parses a Merkle coding shred.
+// Returns nil if the shred is malformed.
 func MerkleCodeFromPayload(shred []byte) *MerkleCode {
-	panic("merkle shred code unimplemented")
+	code := new(MerkleCode)
+	dec := bin.NewBinDecoder(shred)
+	if err := dec.Decode(&code.Common); err != nil {
+		return nil
+	}
+	if err := dec.Decode(&code.Header); err != nil {
+		return nil
+	}
+	if VariantType(code.Common.Variant) != TypeMerkleCode {
+		return nil
+	}
+	if len(shred) < MerkleCodePayloadSize || code.capacity() < 0 {
+		return nil
+	}
+	code.Payload = make([]byte, MerkleCodePayloadSize)
+	copy(code.Payload, shred)
+	return code
 }
 
 func (s *MerkleCode) CommonHeader() *CommonHeader {
@@ -16,6 +90,10 @@ func (s *MerkleCode) DataHeader() *DataHeader {
 	return nil
 }
 
+func (s *MerkleCode) CodingHeader() *CodingHeader {
+	return &s.Header
+}
+
 func (s *MerkleCode) Data() ([]byte, bool) {
 	return nil, false
 }
@@ -24,13 +102,60 @@ func (s *MerkleCode) DataComplete() bool {
 	return false
 }
 
+func (s *MerkleCode) Type() ShredType {
+	return TypeMerkleCode
+}
+
+func (s *MerkleCode) IsMerkle() bool {
+	return true
+}
+
+// MerkleProofDepth returns the number of Merkle proof entries in the shred.
+func (s *MerkleCode) MerkleProofDepth() int {
+	return int(s.Common.Variant & MerkleProofDepthMask)
+}
+
+// RetransmitterSignature returns the signature of the node that retransmitted the shred.
+//
+// Returns false if the shred is not resigned.
+func (s *MerkleCode) RetransmitterSignature() (solana.Signature, bool) {
+	return retransmitterSignature(s.Common.Variant, s.Payload)
+}
+
+// capacity returns the size of the erasure shard.
+func (s *MerkleCode) capacity() int {
+	return MerkleCodePayloadSize - CodingHeadersSize - merkleTrailerSize(s.Common.Variant)
+}
+
 type MerkleData struct {
-	Common CommonHeader
-	Header DataHeader
+	Common  CommonHeader
+	Header  DataHeader
+	Payload []byte
 }
 
+// MerkleDataFromPayload parses a Merkle data shred.
+// Returns nil if the shred is malformed.
 func MerkleDataFromPayload(shred []byte) *MerkleData {
-	panic("merkle shred data unimplemented")
+	data := new(MerkleData)
+	dec := bin.NewBinDecoder(shred)
+	if err := dec.Decode(&data.Common); err != nil {
+		return nil
+	}
+	if err := dec.Decode(&data.Header); err != nil {
+		return nil
+	}
+	if VariantType(data.Common.Variant) != TypeMerkleData {
+		return nil
+	}
+	if len(shred) < MerkleDataPayloadSize || data.capacity() < 0 {
+		return nil
+	}
+	if size := int(data.Header.Size); size < DataHeadersSize || size > DataHeadersSize+data.capacity() {
+		return nil
+	}
+	data.Payload = make([]byte, MerkleDataPayloadSize)
+	copy(data.Payload, shred)
+	return data
 }
 
 func (s *MerkleData) CommonHeader() *CommonHeader {
@@ -41,10 +166,41 @@ func (s *MerkleData) DataHeader() *DataHeader {
 	return &s.Header
 }
 
+// Data returns the entry data carried by the shred,
+// excluding zero padding and the trailing Merkle fields.
 func (s *MerkleData) Data() ([]byte, bool) {
-	panic("MerkleData.Data() unimplemented")
+	size := int(s.Header.Size)
+	if size < DataHeadersSize || size > DataHeadersSize+s.capacity() {
+		return nil, false
+	}
+	return s.Payload[DataHeadersSize:size], true
 }
 
 func (s *MerkleData) DataComplete() bool {
-	return s.Header.Flags&FlagDataCompleteShred == 1
+	return s.Header.Flags&FlagDataCompleteShred == FlagDataCompleteShred
+}
+
+func (s *MerkleData) Type() ShredType {
+	return TypeMerkleData
+}
+
+func (s *MerkleData) IsMerkle() bool {
+	return true
+}
+
+// MerkleProofDepth returns the number of Merkle proof entries in the shred.
+func (s *MerkleData) MerkleProofDepth() int {
+	return int(s.Common.Variant & MerkleProofDepthMask)
+}
+
+// RetransmitterSignature returns the signature of the node that retransmitted the shred.
+//
+// Returns false if the shred is not resigned.
+func (s *MerkleData) RetransmitterSignature() (solana.Signature, bool) {
+	return retransmitterSignature(s.Common.Variant, s.Payload)
+}
+
+// capacity returns the maximum size of the entry data.
+func (s *MerkleData) capacity() int {
+	return MerkleDataPayloadSize - DataHeadersSize - merkleTrailerSize(s.Common.Variant)
 }
@@ -0,0 +1,68 @@
+package shred_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/terorie/solana-blockstore-go/shred"
+)
+
+// resignedDataShred creates a chained resigned Merkle data shred
+// with a nonzero chained root, Merkle proof and retransmitter signature.
+func resignedDataShred(t testing.TB, depth int, data []byte, sig solana.Signature) []byte {
+	t.Helper()
+	variant := shred.MerkleDataChainedResignedID | uint8(depth)
+	payload := encodeDataShred(t, variant, shred.MerkleDataPayloadSize, 0, shred.FlagLastShredInSlot, data)
+	trailer := payload[shred.MerkleDataPayloadSize-32-depth*20-64:]
+	for i := range trailer {
+		trailer[i] = 0xee
+	}
+	copy(payload[len(payload)-64:], sig[:])
+	return payload
+}
+
+func TestMerkleDataChainedResigned(t *testing.T) {
+	const depth = 6
+	const capacity = shred.MerkleDataPayloadSize - shred.DataHeadersSize - 32 - depth*20 - 64
+	sig := solana.Signature{1, 2, 3}
+	for _, size := range []int{10, capacity} {
+		data := bytes.Repeat([]byte{0x11}, size)
+		s := shred.NewShredFromSerialized(resignedDataShred(t, depth, data, sig))
+		merkle, ok := s.(*shred.MerkleData)
+		if !ok {
+			t.Fatalf("parsed %T", s)
+		}
+		if got := merkle.MerkleProofDepth(); got != depth {
+			t.Errorf("proof depth %d", got)
+		}
+		if got, ok := merkle.RetransmitterSignature(); !ok || got != sig {
+			t.Errorf("retransmitter signature %s, %v", got, ok)
+		}
+		got, ok := merkle.Data()
+		if !ok {
+			t.Fatalf("size %d: no data", size)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("size %d: data of %d bytes includes trailer", size, len(got))
+		}
+	}
+}
+
+func TestMerkleDataChainedResignedOversized(t *testing.T) {
+	const depth = 6
+	const capacity = shred.MerkleDataPayloadSize - shred.DataHeadersSize - 32 - depth*20 - 64
+	// Data reaching into the trailer is malformed.
+	payload := resignedDataShred(t, depth, make([]byte, capacity+1), solana.Signature{})
+	if _, err := shred.NewShredFromSerializedErr(payload); err == nil {
+		t.Fatal("parsed shred with data overlapping the Merkle proof")
+	}
+}
+
+func TestMerkleDataRetransmitterSignatureUnsigned(t *testing.T) {
+	payload := encodeDataShred(t, shred.MerkleDataChainedID|6, shred.MerkleDataPayloadSize, 0, 0, []byte{1})
+	merkle := shred.NewShredFromSerialized(payload).(*shred.MerkleData)
+	if _, ok := merkle.RetransmitterSignature(); ok {
+		t.Error("chained shred without resigned variant has a retransmitter signature")
+	}
+}
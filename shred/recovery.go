@@ -0,0 +1,221 @@
+package shred
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrErasureConfigMismatch is returned when the shreds of an erasure set
+// disagree with the expected erasure config.
+var ErrErasureConfigMismatch = errors.New("erasure config mismatch")
+
+// ErasureConfig is the number of data and coding shreds in an erasure set.
+type ErasureConfig struct {
+	NumData   int
+	NumCoding int
+}
+
+// RecoverWithConfig is like Recover,
+// but first checks the shreds against the erasure config of the set,
+// e.g. as recorded in the erasure meta of the blockstore.
+//
+// Returns an error wrapping ErrErasureConfigMismatch if the coding shreds declare different counts,
+// or if there are more data or coding shreds than the config allows.
+func RecoverWithConfig(data []Shred, coding []Shred, cfg ErasureConfig) ([]Shred, error) {
+	if len(data) > cfg.NumData {
+		return nil, fmt.Errorf("%w: %d data shreds, expected at most %d", ErrErasureConfigMismatch, len(data), cfg.NumData)
+	}
+	if len(coding) > cfg.NumCoding {
+		return nil, fmt.Errorf("%w: %d coding shreds, expected at most %d", ErrErasureConfigMismatch, len(coding), cfg.NumCoding)
+	}
+	for _, s := range coding {
+		var header *CodingHeader
+		switch c := s.(type) {
+		case *LegacyCode:
+			header = &c.Header
+		case *MerkleCode:
+			header = &c.Header
+		default:
+			continue // rejected by Recover
+		}
+		if int(header.NumDataShreds) != cfg.NumData || int(header.NumCodingShreds) != cfg.NumCoding {
+			return nil, fmt.Errorf("%w: coding shred %d declares %d data and %d coding shreds, expected %d and %d",
+				ErrErasureConfigMismatch, s.CommonHeader().Index,
+				header.NumDataShreds, header.NumCodingShreds, cfg.NumData, cfg.NumCoding)
+		}
+	}
+	return Recover(data, coding)
+}
+
+// Recover reconstructs the missing data shreds of an erasure set
+// from the available data and coding shreds of that set.
+//
+// Returns only the recovered shreds.
+//
+// Recovered Merkle data shreds carry the entry data, the signature and the chained Merkle root of the set,
+// but neither a Merkle proof nor a retransmitter signature, which are left zero.
+func Recover(data []Shred, coding []Shred) ([]Shred, error) {
+	if len(coding) == 0 {
+		return nil, ErrTooFewShards
+	}
+	switch first := coding[0].(type) {
+	case *LegacyCode:
+		return recoverLegacy(first, data, coding)
+	case *MerkleCode:
+		return recoverMerkle(first, data, coding)
+	default:
+		return nil, fmt.Errorf("not a coding shred: %s", coding[0].Type())
+	}
+}
+
+func recoverLegacy(first *LegacyCode, data []Shred, coding []Shred) ([]Shred, error) {
+	slot := first.Common.Slot
+	fecSetIndex := first.Common.FECSetIndex
+	numData := int(first.Header.NumDataShreds)
+	numCoding := int(first.Header.NumCodingShreds)
+
+	shards := make([][]byte, numData+numCoding)
+	for _, s := range data {
+		d, ok := s.(*LegacyData)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s shred in data shreds", s.Type())
+		}
+		if d.Common.Slot != slot || d.Common.FECSetIndex != fecSetIndex {
+			return nil, fmt.Errorf("data shred %d/%d not in erasure set %d/%d",
+				d.Common.Slot, d.Common.Index, slot, fecSetIndex)
+		}
+		pos := int(d.Common.Index) - int(fecSetIndex)
+		if pos < 0 || pos >= numData {
+			return nil, fmt.Errorf("data shred index %d out of erasure set %d", d.Common.Index, fecSetIndex)
+		}
+		shards[pos] = d.Payload[:LegacyErasureShardSize]
+	}
+	for _, s := range coding {
+		c, ok := s.(*LegacyCode)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s shred in coding shreds", s.Type())
+		}
+		if c.Common.Slot != slot || c.Common.FECSetIndex != fecSetIndex ||
+			int(c.Header.NumDataShreds) != numData || int(c.Header.NumCodingShreds) != numCoding {
+			return nil, fmt.Errorf("coding shred %d/%d inconsistent with erasure set %d/%d",
+				c.Common.Slot, c.Common.Index, slot, fecSetIndex)
+		}
+		pos := int(c.Header.Position)
+		if pos >= numCoding {
+			return nil, fmt.Errorf("coding shred position %d out of bounds", pos)
+		}
+		shards[numData+pos] = c.Payload[CodingHeadersSize:LegacyPayloadSize]
+	}
+
+	missing := make([]bool, numData)
+	for i := range missing {
+		missing[i] = shards[i] == nil
+	}
+	if err := reconstructData(shards, numData); err != nil {
+		return nil, err
+	}
+
+	var recovered []Shred
+	for i, isMissing := range missing {
+		if !isMissing {
+			continue
+		}
+		d := LegacyDataFromPayload(shards[i])
+		if d == nil {
+			return nil, fmt.Errorf("%w: recovered data shred %d", ErrMalformedShred, int(fecSetIndex)+i)
+		}
+		if d.Common.Slot != slot || d.Common.Index != fecSetIndex+uint32(i) {
+			return nil, fmt.Errorf("recovered data shred %d/%d does not match erasure set %d/%d",
+				d.Common.Slot, d.Common.Index, slot, fecSetIndex)
+		}
+		recovered = append(recovered, d)
+	}
+	return recovered, nil
+}
+
+// recoverMerkle is like recoverLegacy for Merkle shreds.
+//
+// The erasure shard of a data shred spans its headers and data following the signature,
+// the one of a coding shred follows its headers.
+// Both exclude the trailing Merkle fields.
+func recoverMerkle(first *MerkleCode, data []Shred, coding []Shred) ([]Shred, error) {
+	variant := first.Common.Variant
+	slot := first.Common.Slot
+	fecSetIndex := first.Common.FECSetIndex
+	numData := int(first.Header.NumDataShreds)
+	numCoding := int(first.Header.NumCodingShreds)
+	shardSize := first.capacity()
+
+	shards := make([][]byte, numData+numCoding)
+	for _, s := range data {
+		d, ok := s.(*MerkleData)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s shred in data shreds", s.Type())
+		}
+		if d.Common.Slot != slot || d.Common.FECSetIndex != fecSetIndex || !sameMerkleLayout(d.Common.Variant, variant) {
+			return nil, fmt.Errorf("data shred %d/%d not in erasure set %d/%d",
+				d.Common.Slot, d.Common.Index, slot, fecSetIndex)
+		}
+		pos := int(d.Common.Index) - int(fecSetIndex)
+		if pos < 0 || pos >= numData {
+			return nil, fmt.Errorf("data shred index %d out of erasure set %d", d.Common.Index, fecSetIndex)
+		}
+		shards[pos] = d.Payload[signatureSize : signatureSize+shardSize]
+	}
+	for _, s := range coding {
+		c, ok := s.(*MerkleCode)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %s shred in coding shreds", s.Type())
+		}
+		if c.Common.Slot != slot || c.Common.FECSetIndex != fecSetIndex || c.Common.Variant != variant ||
+			int(c.Header.NumDataShreds) != numData || int(c.Header.NumCodingShreds) != numCoding {
+			return nil, fmt.Errorf("coding shred %d/%d inconsistent with erasure set %d/%d",
+				c.Common.Slot, c.Common.Index, slot, fecSetIndex)
+		}
+		pos := int(c.Header.Position)
+		if pos >= numCoding {
+			return nil, fmt.Errorf("coding shred position %d out of bounds", pos)
+		}
+		shards[numData+pos] = c.Payload[CodingHeadersSize : CodingHeadersSize+shardSize]
+	}
+
+	missing := make([]bool, numData)
+	for i := range missing {
+		missing[i] = shards[i] == nil
+	}
+	if err := reconstructData(shards, numData); err != nil {
+		return nil, err
+	}
+
+	var recovered []Shred
+	for i, isMissing := range missing {
+		if !isMissing {
+			continue
+		}
+		// All shreds of the set are signed over the same Merkle root.
+		payload := make([]byte, MerkleDataPayloadSize)
+		copy(payload, first.Common.Signature[:])
+		copy(payload[signatureSize:], shards[i])
+		if isChained(variant) {
+			root := first.Payload[CodingHeadersSize+shardSize:][:merkleRootSize]
+			copy(payload[signatureSize+shardSize:], root)
+		}
+		d := MerkleDataFromPayload(payload)
+		if d == nil {
+			return nil, fmt.Errorf("%w: recovered data shred %d", ErrMalformedShred, int(fecSetIndex)+i)
+		}
+		if d.Common.Slot != slot || d.Common.Index != fecSetIndex+uint32(i) || !sameMerkleLayout(d.Common.Variant, variant) {
+			return nil, fmt.Errorf("recovered data shred %d/%d does not match erasure set %d/%d",
+				d.Common.Slot, d.Common.Index, slot, fecSetIndex)
+		}
+		recovered = append(recovered, d)
+	}
+	return recovered, nil
+}
+
+// sameMerkleLayout returns whether two Merkle shred variants have the same trailer,
+// as required for shreds of one erasure set.
+func sameMerkleLayout(a, b uint8) bool {
+	return a&MerkleProofDepthMask == b&MerkleProofDepthMask &&
+		isChained(a) == isChained(b) && isResigned(a) == isResigned(b)
+}
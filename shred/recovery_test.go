@@ -0,0 +1,169 @@
+package shred
+
+import (
+	"bytes"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// encodeParity computes the coding shards of the given data shards.
+func encodeParity(t *testing.T, shards [][]byte, numCoding int) [][]byte {
+	t.Helper()
+	numData := len(shards)
+	encoding, err := encodingMatrix(numData, numData+numCoding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parity := make([][]byte, numCoding)
+	for j := range parity {
+		parity[j] = make([]byte, len(shards[0]))
+		for i, shard := range shards {
+			factor := encoding[numData+j][i]
+			for k, b := range shard {
+				parity[j][k] ^= gfMul(factor, b)
+			}
+		}
+	}
+	return parity
+}
+
+func encodeHeaders(t *testing.T, headers ...any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := bin.NewBinEncoder(&buf)
+	for _, header := range headers {
+		if err := enc.Encode(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestRecoverMerkle(t *testing.T) {
+	const (
+		numData     = 3
+		numCoding   = 2
+		slot        = 7
+		fecSetIndex = 32
+		depth       = 6
+	)
+	dataVariant := MerkleDataChainedResignedID | depth
+	codeVariant := MerkleCodeChainedResignedID | depth
+	sig := solana.Signature{1, 2, 3}
+	root := bytes.Repeat([]byte{0xcc}, merkleRootSize)
+	shardSize := MerkleCodePayloadSize - CodingHeadersSize - merkleTrailerSize(codeVariant)
+
+	var dataShreds []*MerkleData
+	var shards [][]byte
+	for i := 0; i < numData; i++ {
+		entryData := bytes.Repeat([]byte{byte(i + 1)}, 100*(i+1))
+		payload := make([]byte, MerkleDataPayloadSize)
+		copy(payload, encodeHeaders(t,
+			&CommonHeader{Signature: sig, Variant: dataVariant, Slot: slot, Index: fecSetIndex + uint32(i), FECSetIndex: fecSetIndex},
+			&DataHeader{ParentOffset: 1, Size: uint16(DataHeadersSize + len(entryData))},
+		))
+		copy(payload[DataHeadersSize:], entryData)
+		d := MerkleDataFromPayload(payload)
+		if d == nil {
+			t.Fatalf("invalid data shred %d", i)
+		}
+		copy(d.Payload[signatureSize+shardSize:], root)
+		dataShreds = append(dataShreds, d)
+		shards = append(shards, d.Payload[signatureSize:signatureSize+shardSize])
+	}
+
+	var coding []Shred
+	for j, parity := range encodeParity(t, shards, numCoding) {
+		payload := make([]byte, MerkleCodePayloadSize)
+		copy(payload, encodeHeaders(t,
+			&CommonHeader{Signature: sig, Variant: codeVariant, Slot: slot, Index: uint32(j), FECSetIndex: fecSetIndex},
+			&CodingHeader{NumDataShreds: numData, NumCodingShreds: numCoding, Position: uint16(j)},
+		))
+		copy(payload[CodingHeadersSize:], parity)
+		copy(payload[CodingHeadersSize+len(parity):], root)
+		c := MerkleCodeFromPayload(payload)
+		if c == nil {
+			t.Fatalf("invalid coding shred %d", j)
+		}
+		coding = append(coding, c)
+	}
+
+	recovered, err := RecoverWithConfig([]Shred{dataShreds[1]}, coding, ErasureConfig{NumData: numData, NumCoding: numCoding})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != 2 {
+		t.Fatalf("recovered %d shreds", len(recovered))
+	}
+	for k, i := range []int{0, 2} {
+		got, ok := recovered[k].(*MerkleData)
+		if !ok {
+			t.Fatalf("recovered %T", recovered[k])
+		}
+		want := dataShreds[i]
+		if got.Common != want.Common {
+			t.Errorf("shred %d: common header %+v, want %+v", i, got.Common, want.Common)
+		}
+		gotData, _ := got.Data()
+		wantData, _ := want.Data()
+		if !bytes.Equal(gotData, wantData) {
+			t.Errorf("shred %d: recovered %d bytes of data, want %d", i, len(gotData), len(wantData))
+		}
+		if gotRoot := got.Payload[signatureSize+shardSize:][:merkleRootSize]; !bytes.Equal(gotRoot, root) {
+			t.Errorf("shred %d: chained root %x", i, gotRoot)
+		}
+	}
+}
+
+func TestRecoverLegacy(t *testing.T) {
+	const (
+		numData     = 2
+		numCoding   = 2
+		slot        = 7
+		fecSetIndex = 10
+	)
+	var dataShreds []*LegacyData
+	var shards [][]byte
+	for i := 0; i < numData; i++ {
+		entryData := bytes.Repeat([]byte{byte(i + 1)}, 50)
+		payload := make([]byte, LegacyPayloadSize)
+		copy(payload, encodeHeaders(t,
+			&CommonHeader{Variant: LegacyDataID, Slot: slot, Index: fecSetIndex + uint32(i), FECSetIndex: fecSetIndex},
+			&DataHeader{ParentOffset: 1, Size: uint16(DataHeadersSize + len(entryData))},
+		))
+		copy(payload[DataHeadersSize:], entryData)
+		d := LegacyDataFromPayload(payload)
+		if d == nil {
+			t.Fatalf("invalid data shred %d", i)
+		}
+		dataShreds = append(dataShreds, d)
+		shards = append(shards, d.Payload[:LegacyErasureShardSize])
+	}
+	var coding []Shred
+	for j, parity := range encodeParity(t, shards, numCoding) {
+		payload := make([]byte, LegacyPayloadSize)
+		copy(payload, encodeHeaders(t,
+			&CommonHeader{Variant: LegacyCodeID, Slot: slot, Index: fecSetIndex + uint32(j), FECSetIndex: fecSetIndex},
+			&CodingHeader{NumDataShreds: numData, NumCodingShreds: numCoding, Position: uint16(j)},
+		))
+		copy(payload[CodingHeadersSize:], parity)
+		coding = append(coding, LegacyCodeFromPayload(payload))
+	}
+
+	recovered, err := Recover(nil, coding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recovered) != numData {
+		t.Fatalf("recovered %d shreds", len(recovered))
+	}
+	for i, s := range recovered {
+		got, _ := s.Data()
+		want, _ := dataShreds[i].Data()
+		if !bytes.Equal(got, want) {
+			t.Errorf("shred %d: recovered %d bytes of data, want %d", i, len(got), len(want))
+		}
+	}
+}
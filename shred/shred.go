@@ -1,12 +1,71 @@
 package shred
 
-import "github.com/gagliardetto/solana-go"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gagliardetto/solana-go"
+)
 
 type Shred interface {
 	CommonHeader() *CommonHeader
 	DataHeader() *DataHeader
+	// Data returns the entry data of a data shred, truncated to DataHeader.Size.
 	Data() ([]byte, bool)
 	DataComplete() bool
+	Type() ShredType
+	IsMerkle() bool
+}
+
+// ShredType identifies the layout of a shred.
+type ShredType uint8
+
+const (
+	TypeUnknown ShredType = iota
+	TypeLegacyData
+	TypeLegacyCode
+	TypeMerkleData
+	TypeMerkleCode
+)
+
+func (t ShredType) String() string {
+	switch t {
+	case TypeLegacyData:
+		return "LegacyData"
+	case TypeLegacyCode:
+		return "LegacyCode"
+	case TypeMerkleData:
+		return "MerkleData"
+	case TypeMerkleCode:
+		return "MerkleCode"
+	default:
+		return "Unknown"
+	}
+}
+
+// IsData returns whether the shred type carries entry data.
+func (t ShredType) IsData() bool {
+	return t == TypeLegacyData || t == TypeMerkleData
+}
+
+// VariantType returns the shred type given the variant byte of the common header.
+func VariantType(variant uint8) ShredType {
+	switch {
+	case variant == LegacyCodeID:
+		return TypeLegacyCode
+	case variant == LegacyDataID:
+		return TypeLegacyData
+	case variant&MerkleMask == MerkleCodeID,
+		variant&MerkleMask == MerkleCodeChainedID,
+		variant&MerkleMask == MerkleCodeChainedResignedID:
+		return TypeMerkleCode
+	case variant&MerkleMask == MerkleDataID,
+		variant&MerkleMask == MerkleDataChainedID,
+		variant&MerkleMask == MerkleDataChainedResignedID:
+		return TypeMerkleData
+	default:
+		return TypeUnknown
+	}
 }
 
 const (
@@ -15,6 +74,15 @@ const (
 	MerkleMask   = uint8(0xF0)
 	MerkleCodeID = uint8(0x40)
 	MerkleDataID = uint8(0x80)
+
+	// Chained Merkle shreds include the Merkle root of the previous erasure set.
+	// Resigned Merkle shreds additionally carry a retransmitter signature.
+	MerkleCodeChainedID         = uint8(0x60)
+	MerkleCodeChainedResignedID = uint8(0x70)
+	MerkleDataChainedID         = uint8(0x90)
+	MerkleDataChainedResignedID = uint8(0xB0)
+
+	MerkleProofDepthMask = uint8(0x0F)
 )
 
 const (
@@ -23,23 +91,49 @@ const (
 	FlagLastShredInSlot        = uint8(0b1100_0000)
 )
 
+var (
+	ErrShredTooShort       = errors.New("shred too short")
+	ErrUnknownShredVariant = errors.New("unknown shred variant")
+	ErrMalformedShred      = errors.New("malformed shred")
+)
+
+// NewShredFromSerialized parses a shred, returning nil if it is invalid.
 func NewShredFromSerialized(shred []byte) Shred {
+	s, _ := NewShredFromSerializedErr(shred)
+	return s
+}
+
+// NewShredFromSerializedErr parses a shred.
+func NewShredFromSerializedErr(shred []byte) (Shred, error) {
 	if len(shred) < 65 {
-		return nil
+		return nil, ErrShredTooShort
 	}
 	variant := shred[64]
-	switch {
-	case variant == LegacyCodeID:
-		return LegacyCodeFromPayload(shred)
-	case variant == LegacyDataID:
-		return LegacyDataFromPayload(shred)
-	case variant&MerkleMask == MerkleCodeID:
-		return MerkleCodeFromPayload(shred)
-	case variant&MerkleMask == MerkleDataID:
-		return MerkleDataFromPayload(shred)
+	var s Shred
+	switch t := VariantType(variant); t {
+	case TypeLegacyCode:
+		if c := LegacyCodeFromPayload(shred); c != nil {
+			s = c
+		}
+	case TypeLegacyData:
+		if d := LegacyDataFromPayload(shred); d != nil {
+			s = d
+		}
+	case TypeMerkleCode:
+		if c := MerkleCodeFromPayload(shred); c != nil {
+			s = c
+		}
+	case TypeMerkleData:
+		if d := MerkleDataFromPayload(shred); d != nil {
+			s = d
+		}
 	default:
-		return nil
+		return nil, fmt.Errorf("%w: 0x%02x", ErrUnknownShredVariant, variant)
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w: variant 0x%02x", ErrMalformedShred, variant)
 	}
+	return s, nil
 }
 
 type CommonHeader struct {
@@ -51,12 +145,30 @@ type CommonHeader struct {
 	FECSetIndex uint32
 }
 
+// Size of the common and data shred headers.
+//
+// DataHeader.Size includes these headers.
+const DataHeadersSize = 88
+
+// Size of the common and coding shred headers.
+const CodingHeadersSize = 89
+
+type CodingHeader struct {
+	NumDataShreds   uint16
+	NumCodingShreds uint16
+	Position        uint16
+}
+
 type DataHeader struct {
 	ParentOffset uint16
 	Flags        uint8
 	Size         uint16
 }
 
+// LastInSlot returns whether the shred is the last data shred of its slot.
+//
+// FlagLastShredInSlot includes the bit of FlagDataCompleteShred,
+// so both bits must be set.
 func (d *DataHeader) LastInSlot() bool {
-	return d.Flags&FlagLastShredInSlot != 0
+	return d.Flags&FlagLastShredInSlot == FlagLastShredInSlot
 }
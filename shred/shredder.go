@@ -8,10 +8,24 @@ import (
 
 var ErrTooFewDataShreds = errors.New("too few data shreds")
 
+// ErrNotDataShred is returned when a coding shred is passed to Deshred or DeshredAll.
+var ErrNotDataShred = errors.New("not a data shred")
+
+// ErrTooManyDataShreds is returned when the data shreds passed to Deshred span multiple data sets.
+var ErrTooManyDataShreds = errors.New("too many data shreds")
+
+// Deshred reassembles the payload of a single data set
+// from its consecutive data shreds.
+//
+// The last shred must complete the data set, and no other shred may.
+// See DeshredAll for shreds spanning multiple data sets.
 func Deshred(shreds []Shred) ([]byte, error) {
 	if len(shreds) == 0 {
 		return nil, ErrTooFewDataShreds
 	}
+	if err := checkDataShreds(shreds); err != nil {
+		return nil, err
+	}
 
 	index := shreds[0].CommonHeader().Index
 	aligned := true
@@ -21,21 +35,74 @@ func Deshred(shreds []Shred) ([]byte, error) {
 			break
 		}
 	}
-	lastShred := shreds[len(shreds)-1]
-	dataComplete := lastShred.DataComplete() || lastShred.DataHeader().LastInSlot()
-	if !dataComplete || !aligned {
+	if !endsDataSet(shreds[len(shreds)-1]) || !aligned {
 		return nil, ErrTooFewDataShreds
 	}
+	for _, shred := range shreds[:len(shreds)-1] {
+		if endsDataSet(shred) {
+			return nil, fmt.Errorf("%w: data set ends at shred %d", ErrTooManyDataShreds, shred.CommonHeader().Index)
+		}
+	}
 
 	var buf bytes.Buffer
 	for _, shred := range shreds {
+		// Data excludes the zero padding of shreds that are not completely filled.
 		data, ok := shred.Data()
 		if !ok {
-			return nil, fmt.Errorf("invalid data shred")
+			return nil, fmt.Errorf("invalid data shred size %d", shred.DataHeader().Size)
 		}
 		buf.Write(data)
 	}
-	// TODO Some empty shred handling idk
 
 	return buf.Bytes(), nil
 }
+
+// DeshredAll reassembles the payloads of consecutive data sets,
+// splitting the shreds after each shred that completes a data set.
+//
+// Returns one payload per data set, in order.
+// Returns ErrTooFewDataShreds if the last data set is incomplete.
+func DeshredAll(shreds []Shred) ([][]byte, error) {
+	if len(shreds) == 0 {
+		return nil, ErrTooFewDataShreds
+	}
+	if err := checkDataShreds(shreds); err != nil {
+		return nil, err
+	}
+	var payloads [][]byte
+	start := 0
+	for i, shred := range shreds {
+		if !endsDataSet(shred) {
+			continue
+		}
+		payload, err := Deshred(shreds[start : i+1])
+		if err != nil {
+			return nil, fmt.Errorf("data set at shred %d: %w", shreds[start].CommonHeader().Index, err)
+		}
+		payloads = append(payloads, payload)
+		start = i + 1
+	}
+	if start < len(shreds) {
+		return nil, ErrTooFewDataShreds
+	}
+	return payloads, nil
+}
+
+// endsDataSet returns whether a data shred is the last of its data set.
+//
+// FlagLastShredInSlot includes FlagDataCompleteShred,
+// so the LastInSlot check only guards against shreds setting the flags inconsistently.
+func endsDataSet(shred Shred) bool {
+	return shred.DataComplete() || shred.DataHeader().LastInSlot()
+}
+
+// checkDataShreds returns an error wrapping ErrNotDataShred if any shred is a coding shred,
+// which lacks a data header.
+func checkDataShreds(shreds []Shred) error {
+	for _, shred := range shreds {
+		if !shred.Type().IsData() || shred.DataHeader() == nil {
+			return fmt.Errorf("%w: %s shred %d", ErrNotDataShred, shred.Type(), shred.CommonHeader().Index)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package shred_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/terorie/solana-blockstore-go/shred"
+)
+
+// encodeDataShred serializes a data shred with the given variant,
+// zero-padded to payloadSize.
+func encodeDataShred(t testing.TB, variant uint8, payloadSize int, index uint32, flags uint8, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := bin.NewBinEncoder(&buf)
+	common := shred.CommonHeader{Variant: variant, Slot: 1, Index: index}
+	header := shred.DataHeader{ParentOffset: 1, Flags: flags, Size: uint16(shred.DataHeadersSize + len(data))}
+	if err := enc.Encode(&common); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(&header); err != nil {
+		t.Fatal(err)
+	}
+	buf.Write(data)
+	payload := make([]byte, payloadSize)
+	copy(payload, buf.Bytes())
+	return payload
+}
+
+// legacyData creates a zero-padded legacy data shred of slot 1.
+func legacyData(t testing.TB, index uint32, flags uint8, data []byte) shred.Shred {
+	t.Helper()
+	s, err := shred.NewShredFromSerializedErr(encodeDataShred(t, shred.LegacyDataID, shred.LegacyPayloadSize, index, flags, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDeshred(t *testing.T) {
+	const (
+		none     = uint8(0)
+		complete = shred.FlagDataCompleteShred
+		last     = shred.FlagLastShredInSlot
+	)
+	tests := []struct {
+		name    string
+		indexes []uint32
+		flags   []uint8
+		want    []byte
+		err     error
+	}{
+		{name: "Empty", err: shred.ErrTooFewDataShreds},
+		{name: "LastInSlot", indexes: []uint32{0}, flags: []uint8{last}, want: []byte{0}},
+		{name: "DataComplete", indexes: []uint32{0}, flags: []uint8{complete}, want: []byte{0}},
+		{name: "Multiple", indexes: []uint32{3, 4, 5}, flags: []uint8{none, none, complete}, want: []byte{3, 4, 5}},
+		{name: "Incomplete", indexes: []uint32{0, 1}, flags: []uint8{none, none}, err: shred.ErrTooFewDataShreds},
+		{name: "Gap", indexes: []uint32{0, 2}, flags: []uint8{none, last}, err: shred.ErrTooFewDataShreds},
+		{name: "IntermediateDataComplete", indexes: []uint32{0, 1}, flags: []uint8{complete, last}, err: shred.ErrTooManyDataShreds},
+		{name: "IntermediateLastInSlot", indexes: []uint32{0, 1}, flags: []uint8{last, complete}, err: shred.ErrTooManyDataShreds},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var shreds []shred.Shred
+			for i, index := range tc.indexes {
+				shreds = append(shreds, legacyData(t, index, tc.flags[i], []byte{byte(index)}))
+			}
+			got, err := shred.Deshred(shreds)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeshredAll(t *testing.T) {
+	const (
+		none     = uint8(0)
+		complete = shred.FlagDataCompleteShred
+		last     = shred.FlagLastShredInSlot
+	)
+	tests := []struct {
+		name  string
+		flags []uint8
+		want  [][]byte
+		err   error
+	}{
+		{name: "Empty", err: shred.ErrTooFewDataShreds},
+		{name: "Single", flags: []uint8{none, last}, want: [][]byte{{0, 1}}},
+		{name: "DataComplete", flags: []uint8{none, complete, none, last}, want: [][]byte{{0, 1}, {2, 3}}},
+		{name: "Adjacent", flags: []uint8{complete, complete, last}, want: [][]byte{{0}, {1}, {2}}},
+		{name: "Trailing", flags: []uint8{complete, none}, err: shred.ErrTooFewDataShreds},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var shreds []shred.Shred
+			for i, flags := range tc.flags {
+				shreds = append(shreds, legacyData(t, uint32(i), flags, []byte{byte(i)}))
+			}
+			got, err := shred.DeshredAll(shreds)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d payloads, want %d", len(got), len(tc.want))
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], tc.want[i]) {
+					t.Errorf("payload %d: got %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDeshredAllGap(t *testing.T) {
+	shreds := []shred.Shred{
+		legacyData(t, 0, shred.FlagDataCompleteShred, []byte{0}),
+		legacyData(t, 1, 0, []byte{1}),
+		legacyData(t, 3, shred.FlagLastShredInSlot, []byte{3}),
+	}
+	if _, err := shred.DeshredAll(shreds); !errors.Is(err, shred.ErrTooFewDataShreds) {
+		t.Fatalf("got error %v, want %v", err, shred.ErrTooFewDataShreds)
+	}
+}
+
+func TestDeshredPartialLastShred(t *testing.T) {
+	const capacity = shred.LegacyPayloadSize - shred.LegacyHeaderSize
+	full := bytes.Repeat([]byte{0xaa}, capacity)
+	// Trailing zeros of the data must survive, only the padding past Size is dropped.
+	partial := []byte{1, 2, 0, 0}
+	shreds := []shred.Shred{
+		legacyData(t, 0, 0, full),
+		legacyData(t, 1, shred.FlagLastShredInSlot, partial),
+	}
+	got, err := shred.Deshred(shreds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte{}, full...), partial...)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %d bytes, want %d", len(got), len(want))
+	}
+}
+
+// shredPayload splits data into data shreds of the given variant and capacity,
+// the last one flagged as the last in the slot.
+func shredPayload(t testing.TB, variant uint8, payloadSize, capacity int, data []byte) []shred.Shred {
+	t.Helper()
+	var shreds []shred.Shred
+	for index := uint32(0); len(data) > 0; index++ {
+		n := len(data)
+		var flags uint8
+		if n > capacity {
+			n = capacity
+		} else {
+			flags = shred.FlagLastShredInSlot
+		}
+		s, err := shred.NewShredFromSerializedErr(encodeDataShred(t, variant, payloadSize, index, flags, data[:n]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shreds = append(shreds, s)
+		data = data[n:]
+	}
+	return shreds
+}
+
+func TestDeshredRoundTrip(t *testing.T) {
+	data := make([]byte, 5000)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+	tests := []struct {
+		name        string
+		variant     uint8
+		payloadSize int
+		capacity    int
+	}{
+		{
+			name:        "Legacy",
+			variant:     shred.LegacyDataID,
+			payloadSize: shred.LegacyPayloadSize,
+			capacity:    shred.LegacyPayloadSize - shred.LegacyHeaderSize,
+		},
+		{
+			name:        "Merkle",
+			variant:     shred.MerkleDataID | 6,
+			payloadSize: shred.MerkleDataPayloadSize,
+			capacity:    shred.MerkleDataPayloadSize - shred.DataHeadersSize - 6*20,
+		},
+		{
+			name:        "MerkleChainedResigned",
+			variant:     shred.MerkleDataChainedResignedID | 6,
+			payloadSize: shred.MerkleDataPayloadSize,
+			capacity:    shred.MerkleDataPayloadSize - shred.DataHeadersSize - 32 - 6*20 - 64,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			shreds := shredPayload(t, tc.variant, tc.payloadSize, tc.capacity, data)
+			if want := (len(data) + tc.capacity - 1) / tc.capacity; len(shreds) != want {
+				t.Fatalf("got %d shreds, want %d", len(shreds), want)
+			}
+			got, err := shred.Deshred(shreds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("deshredded %d bytes, not matching the %d bytes shredded", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestDeshredCodingShred(t *testing.T) {
+	var buf bytes.Buffer
+	enc := bin.NewBinEncoder(&buf)
+	if err := enc.Encode(&shred.CommonHeader{Variant: shred.LegacyCodeID, Slot: 1, Index: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Encode(&shred.CodingHeader{NumDataShreds: 1, NumCodingShreds: 1}); err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, shred.LegacyPayloadSize)
+	copy(payload, buf.Bytes())
+	code, err := shred.NewShredFromSerializedErr(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shreds := []shred.Shred{legacyData(t, 0, 0, []byte{0}), code}
+	if _, err := shred.Deshred(shreds); !errors.Is(err, shred.ErrNotDataShred) {
+		t.Errorf("Deshred: got error %v, want %v", err, shred.ErrNotDataShred)
+	}
+	if _, err := shred.DeshredAll(shreds); !errors.Is(err, shred.ErrNotDataShred) {
+		t.Errorf("DeshredAll: got error %v, want %v", err, shred.ErrNotDataShred)
+	}
+}
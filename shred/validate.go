@@ -0,0 +1,92 @@
+package shred
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxDataShredsPerSlot is the maximum number of data shreds in a slot.
+const MaxDataShredsPerSlot = 32768
+
+var ErrInvalidShred = errors.New("invalid shred")
+
+// Validate checks the header invariants of a shred,
+// similar to the sanitization of shreds received by the validator.
+//
+// Returns an error wrapping ErrInvalidShred describing the first violated invariant.
+func Validate(s Shred) error {
+	common := s.CommonHeader()
+	if err := validateCommon(s.Type(), common); err != nil {
+		return fmt.Errorf("%w: shred %d/%d: %v", ErrInvalidShred, common.Slot, common.Index, err)
+	}
+	var err error
+	switch s := s.(type) {
+	case *LegacyData:
+		err = validateData(common, &s.Header, LegacyPayloadSize)
+	case *MerkleData:
+		err = validateData(common, &s.Header, DataHeadersSize+s.capacity())
+	case *LegacyCode:
+		err = validateCode(common, &s.Header)
+	case *MerkleCode:
+		err = validateCode(common, &s.Header)
+	default:
+		err = fmt.Errorf("unsupported shred type %s", s.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("%w: shred %d/%d: %v", ErrInvalidShred, common.Slot, common.Index, err)
+	}
+	return nil
+}
+
+func validateCommon(t ShredType, common *CommonHeader) error {
+	if VariantType(common.Variant) != t {
+		return fmt.Errorf("variant 0x%02x does not match shred type %s", common.Variant, t)
+	}
+	if t.IsData() {
+		if common.Index >= MaxDataShredsPerSlot {
+			return fmt.Errorf("index exceeds %d data shreds per slot", MaxDataShredsPerSlot)
+		}
+		if common.Index < common.FECSetIndex {
+			return fmt.Errorf("index below erasure set index %d", common.FECSetIndex)
+		}
+	}
+	return nil
+}
+
+func validateData(common *CommonHeader, header *DataHeader, maxSize int) error {
+	if size := int(header.Size); size < DataHeadersSize || size > maxSize {
+		return fmt.Errorf("data size %d out of bounds [%d, %d]", size, DataHeadersSize, maxSize)
+	}
+	offset := uint64(header.ParentOffset)
+	switch {
+	case common.Slot == 0 && offset != 0:
+		return fmt.Errorf("nonzero parent offset %d in genesis slot", offset)
+	case common.Slot > 0 && offset == 0:
+		return errors.New("zero parent offset")
+	case offset > common.Slot:
+		return fmt.Errorf("parent offset %d exceeds slot", offset)
+	}
+	return nil
+}
+
+func validateCode(common *CommonHeader, header *CodingHeader) error {
+	numData := int(header.NumDataShreds)
+	numCoding := int(header.NumCodingShreds)
+	if numData == 0 || numCoding == 0 {
+		return fmt.Errorf("empty erasure set with %d data and %d coding shreds", numData, numCoding)
+	}
+	if numData+numCoding > 256 {
+		return fmt.Errorf("erasure set with %d data and %d coding shreds exceeds 256 shards", numData, numCoding)
+	}
+	if header.Position >= header.NumCodingShreds {
+		return fmt.Errorf("position %d out of %d coding shreds", header.Position, numCoding)
+	}
+	if uint32(header.Position) > common.Index {
+		return fmt.Errorf("position %d exceeds index", header.Position)
+	}
+	if int(common.FECSetIndex)+numData > MaxDataShredsPerSlot {
+		return fmt.Errorf("erasure set %d with %d data shreds exceeds %d data shreds per slot",
+			common.FECSetIndex, numData, MaxDataShredsPerSlot)
+	}
+	return nil
+}
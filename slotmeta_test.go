@@ -0,0 +1,48 @@
+package blockstore_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+func TestSlotMetaSlot(t *testing.T) {
+	slots := []uint64{7, 3, 5}
+	b := testutil.NewLedger(t)
+	for _, slot := range slots {
+		b.AddBlock(slot, slot-1, []blockstore.Entry{{NumHashes: 1, Hash: solana.Hash{byte(slot)}}})
+	}
+	db, err := blockstore.OpenReadOnly(b.Path())
+	if err != nil {
+		t.Fatalf("cannot open ledger: %s", err)
+	}
+	defer db.Close()
+
+	for _, slot := range slots {
+		meta, err := db.GetSlotMeta(slot)
+		if err != nil {
+			t.Fatalf("GetSlotMeta(%d): %s", slot, err)
+		}
+		if meta.Slot != slot {
+			t.Errorf("GetSlotMeta(%d) returned slot %d", slot, meta.Slot)
+		}
+	}
+
+	metas, err := db.MultiGetSlotMeta(slots...)
+	if err != nil {
+		t.Fatalf("MultiGetSlotMeta: %s", err)
+	}
+	if len(metas) != len(slots) {
+		t.Fatalf("MultiGetSlotMeta returned %d metas", len(metas))
+	}
+	for i, meta := range metas {
+		if meta.Slot != slots[i] {
+			t.Errorf("MultiGetSlotMeta: meta %d has slot %d, want %d", i, meta.Slot, slots[i])
+		}
+		if meta.ParentSlot != slots[i]-1 {
+			t.Errorf("MultiGetSlotMeta: meta %d has parent slot %d, want %d", i, meta.ParentSlot, slots[i]-1)
+		}
+	}
+}
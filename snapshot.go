@@ -0,0 +1,42 @@
+package blockstore
+
+import "github.com/linxGnu/grocksdb"
+
+// Snapshot is a consistent point-in-time view of a DB.
+//
+// All read methods of DB are available on Snapshot and observe the state of the ledger
+// at the time NewSnapshot was called, even if TryCatchUpWithPrimary runs concurrently.
+// This prevents torn reads, e.g. a slot meta and shreds written after it was read.
+//
+// Like DB, a Snapshot is safe for concurrent use.
+// Release must be called once the snapshot is no longer needed,
+// and before the underlying DB is closed.
+type Snapshot struct {
+	*DB
+}
+
+// NewSnapshot creates a snapshot of the current state of the database.
+func (d *DB) NewSnapshot() *Snapshot {
+	snap := d.db.NewSnapshot()
+	view := *d
+	view.snapshot = snap
+	view.metaCache = nil // entries may be newer than the snapshot
+	view.readOpts = grocksdb.NewDefaultReadOptions()
+	view.readOpts.SetSnapshot(snap)
+	view.iterOpts = view.newIterReadOptions()
+	return &Snapshot{&view}
+}
+
+// Release frees the snapshot.
+// Iterators created from the snapshot must be closed beforehand.
+func (s *Snapshot) Release() {
+	s.readOpts.Destroy()
+	s.iterOpts.Destroy()
+	s.db.ReleaseSnapshot(s.snapshot)
+}
+
+// Close is an alias for Release.
+// It does not close the underlying DB.
+func (s *Snapshot) Close() {
+	s.Release()
+}
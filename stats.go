@@ -0,0 +1,171 @@
+package blockstore
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/linxGnu/grocksdb"
+	"github.com/terorie/solana-blockstore-go/shred"
+)
+
+// DBStats holds RocksDB metrics of each opened column family, keyed by name.
+type DBStats struct {
+	ColumnFamilies map[string]CFStats
+}
+
+// CFStats holds RocksDB metrics of a column family.
+type CFStats struct {
+	EstimatedKeys uint64 // rocksdb.estimate-num-keys
+	LiveDataSize  uint64 // rocksdb.estimate-live-data-size
+	SSTFilesSize  uint64 // rocksdb.total-sst-files-size
+	NumSSTFiles   uint64 // sum of rocksdb.num-files-at-level<N>
+}
+
+// Number of LSM levels in a RocksDB database with default options.
+const numLevels = 7
+
+// Stats returns RocksDB metrics of each opened column family.
+//
+// Values are estimates maintained by RocksDB and cheap to retrieve.
+func (d *DB) Stats() (DBStats, error) {
+	stats := DBStats{ColumnFamilies: make(map[string]CFStats, len(d.cfs))}
+	for name, cf := range d.cfs {
+		var s CFStats
+		var ok bool
+		if s.EstimatedKeys, ok = d.db.GetIntPropertyCF("rocksdb.estimate-num-keys", cf); !ok {
+			return stats, fmt.Errorf("failed to get key estimate of %s", name)
+		}
+		if s.LiveDataSize, ok = d.db.GetIntPropertyCF("rocksdb.estimate-live-data-size", cf); !ok {
+			return stats, fmt.Errorf("failed to get live data size of %s", name)
+		}
+		if s.SSTFilesSize, ok = d.db.GetIntPropertyCF("rocksdb.total-sst-files-size", cf); !ok {
+			return stats, fmt.Errorf("failed to get SST files size of %s", name)
+		}
+		for level := 0; level < numLevels; level++ {
+			prop := d.db.GetPropertyCF(fmt.Sprintf("rocksdb.num-files-at-level%d", level), cf)
+			numFiles, err := strconv.ParseUint(prop, 10, 64)
+			if err != nil {
+				return stats, fmt.Errorf("invalid file count of %s at level %d: %q", name, level, prop)
+			}
+			s.NumSSTFiles += numFiles
+		}
+		stats.ColumnFamilies[name] = s
+	}
+	return stats, nil
+}
+
+// EstimateSlotRange returns the lowest and highest slot with a slot meta.
+func (d *DB) EstimateSlotRange() (first, last uint64, err error) {
+	iter := d.IterSlotMetas(nil)
+	defer iter.Close()
+	iter.SeekToFirst()
+	if !iter.Valid() {
+		return 0, 0, ErrNotFound
+	}
+	if first, err = ParseSlotKey(iter.Key().Data()); err != nil {
+		return
+	}
+	iter.SeekToLast()
+	if !iter.Valid() {
+		return 0, 0, ErrNotFound
+	}
+	last, err = ParseSlotKey(iter.Key().Data())
+	return
+}
+
+// ApproxSizeForSlotRange estimates the on-disk size in bytes
+// of the data and coding shreds in the slot range [start, end].
+func (d *DB) ApproxSizeForSlotRange(start, end uint64) (uint64, error) {
+	if start > end {
+		return 0, fmt.Errorf("invalid slot range [%d, %d]", start, end)
+	}
+	startKey := MakeSlotKey(start)
+	limit := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	if end < math.MaxUint64 {
+		endKey := MakeSlotKey(end + 1)
+		limit = endKey[:]
+	}
+	ranges := []grocksdb.Range{{Start: startKey[:], Limit: limit}}
+	var total uint64
+	for _, cf := range []*grocksdb.ColumnFamilyHandle{d.cfDataShred, d.cfCodeShred} {
+		sizes, err := d.db.GetApproximateSizesCF(cf, ranges)
+		if err != nil {
+			return 0, err
+		}
+		total += sizes[0]
+	}
+	return total, nil
+}
+
+// Approximate on-disk size of a stored shred, including its key.
+const approxStoredShredSize = shred.LegacyPayloadSize + 16
+
+// EstimateShredCount estimates the number of data and coding shreds in a slot
+// from the approximate on-disk size of its shreds, without reading them.
+//
+// The estimate only accounts for shreds flushed to table files
+// and is coarse for small slots.
+func (d *DB) EstimateShredCount(slot uint64) (uint64, error) {
+	size, err := d.ApproxSizeForSlotRange(slot, slot)
+	if err != nil {
+		return 0, err
+	}
+	return (size + approxStoredShredSize/2) / approxStoredShredSize, nil
+}
+
+// LedgerInfo is a quick fingerprint of a ledger.
+type LedgerInfo struct {
+	LowestSlot  uint64  `yaml:"lowest_slot" json:"lowest_slot"`   // lowest slot with a slot meta
+	HighestSlot uint64  `yaml:"highest_slot" json:"highest_slot"` // highest slot with a slot meta
+	MaxRoot     *uint64 `yaml:"max_root" json:"max_root"`         // nil if there are no roots
+
+	// HasDataShreds is false if the ledger has no data shreds,
+	// in which case ShredVersion and Merkle are unset.
+	HasDataShreds bool `yaml:"has_data_shreds" json:"has_data_shreds"`
+	// ShredVersion is the version of the newest data shred.
+	ShredVersion uint16 `yaml:"shred_version" json:"shred_version"`
+	// Merkle is whether the newest data shred is a Merkle shred rather than a legacy shred.
+	Merkle bool `yaml:"merkle" json:"merkle"`
+}
+
+// LedgerInfo gathers the slot range, max root, and shred format of the ledger.
+//
+// Returns ErrNotFound if the ledger has no slot metas.
+func (d *DB) LedgerInfo() (*LedgerInfo, error) {
+	info := new(LedgerInfo)
+	var err error
+	if info.LowestSlot, info.HighestSlot, err = d.EstimateSlotRange(); err != nil {
+		return nil, err
+	}
+	if root, err := d.MaxRoot(); err == nil {
+		info.MaxRoot = &root
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	iter := d.iterShreds(nil, d.cfDataShred)
+	defer iter.Close()
+	iter.SeekToLast()
+	if !iter.Valid() {
+		return info, iter.Err()
+	}
+	value := iter.Value().Data()
+	d.limiter.charge(len(value))
+	var header shred.CommonHeader
+	if err := bin.NewBinDecoder(value).Decode(&header); err != nil {
+		return nil, fmt.Errorf("invalid data shred %x: %w", iter.Key().Data(), err)
+	}
+	info.HasDataShreds = true
+	info.ShredVersion = header.Version
+	switch shred.VariantType(header.Variant) {
+	case shred.TypeMerkleData:
+		info.Merkle = true
+	case shred.TypeLegacyData:
+	default:
+		return nil, fmt.Errorf("invalid data shred %x: unexpected variant 0x%02x", iter.Key().Data(), header.Variant)
+	}
+	return info, nil
+}
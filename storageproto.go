@@ -0,0 +1,248 @@
+package blockstore
+
+import (
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ToStorageProto serializes a block to the solana.storage.ConfirmedBlock protobuf message,
+// as stored by solana-storage-bigtable.
+func ToStorageProto(block *ConfirmedBlock) ([]byte, error) {
+	var b []byte
+	b = appendProtoString(b, 1, block.PreviousBlockhash.String())
+	b = appendProtoString(b, 2, block.Blockhash.String())
+	b = appendProtoVarint(b, 3, block.ParentSlot)
+	for i := range block.Transactions {
+		tx, err := encodeConfirmedTransaction(&block.Transactions[i])
+		if err != nil {
+			return nil, err
+		}
+		b = appendProtoElem(b, 4, tx)
+	}
+	for i := range block.Rewards {
+		b = appendProtoElem(b, 5, encodeReward(&block.Rewards[i]))
+	}
+	if block.BlockTime != nil {
+		var ts []byte
+		ts = appendProtoVarint(ts, 1, uint64(*block.BlockTime))
+		b = appendProtoElem(b, 6, ts)
+	}
+	if block.BlockHeight != nil {
+		var height []byte
+		height = appendProtoVarint(height, 1, *block.BlockHeight)
+		b = appendProtoElem(b, 7, height)
+	}
+	return b, nil
+}
+
+// encodeConfirmedTransaction encodes a solana.storage.ConfirmedBlock.ConfirmedTransaction.
+func encodeConfirmedTransaction(tx *TransactionWithMeta) ([]byte, error) {
+	var b []byte
+	b = appendProtoElem(b, 1, encodeTransaction(&tx.Transaction))
+	if tx.Meta != nil {
+		b = appendProtoElem(b, 2, encodeTransactionStatusMeta(tx.Meta))
+	}
+	return b, nil
+}
+
+// encodeTransaction encodes a solana.storage.ConfirmedBlock.Transaction.
+func encodeTransaction(tx *Transaction) []byte {
+	var b []byte
+	for _, sig := range tx.Signatures {
+		b = appendProtoElem(b, 1, sig[:])
+	}
+
+	msg := &tx.Message
+	var header []byte
+	header = appendProtoVarint(header, 1, uint64(msg.Header.NumRequiredSignatures))
+	header = appendProtoVarint(header, 2, uint64(msg.Header.NumReadonlySignedAccounts))
+	header = appendProtoVarint(header, 3, uint64(msg.Header.NumReadonlyUnsignedAccounts))
+
+	var m []byte
+	m = appendProtoElem(m, 1, header)
+	for _, key := range msg.AccountKeys {
+		m = appendProtoElem(m, 2, key[:])
+	}
+	m = appendProtoBytes(m, 3, msg.RecentBlockhash[:])
+	for _, ins := range msg.Instructions {
+		accounts := make([]byte, len(ins.Accounts))
+		for i, account := range ins.Accounts {
+			accounts[i] = uint8(account)
+		}
+		var ib []byte
+		ib = appendProtoVarint(ib, 1, uint64(ins.ProgramIDIndex))
+		ib = appendProtoBytes(ib, 2, accounts)
+		ib = appendProtoBytes(ib, 3, ins.Data)
+		m = appendProtoElem(m, 4, ib)
+	}
+	if tx.Versioned {
+		m = appendProtoBool(m, 5, true)
+	}
+	for _, lookup := range tx.AddressTableLookups {
+		var lb []byte
+		lb = appendProtoBytes(lb, 1, lookup.AccountKey[:])
+		lb = appendProtoBytes(lb, 2, lookup.WritableIndexes)
+		lb = appendProtoBytes(lb, 3, lookup.ReadonlyIndexes)
+		m = appendProtoElem(m, 6, lb)
+	}
+	return appendProtoElem(b, 2, m)
+}
+
+// encodeTransactionStatusMeta encodes a solana.storage.ConfirmedBlock.TransactionStatusMeta.
+func encodeTransactionStatusMeta(meta *TransactionStatusMeta) []byte {
+	var b []byte
+	if meta.Err != nil {
+		var txErr []byte
+		txErr = appendProtoBytes(txErr, 1, meta.Err)
+		b = appendProtoElem(b, 1, txErr)
+	}
+	b = appendProtoVarint(b, 2, meta.Fee)
+	b = appendProtoPackedUint64s(b, 3, meta.PreBalances)
+	b = appendProtoPackedUint64s(b, 4, meta.PostBalances)
+	for i := range meta.InnerInstructions {
+		b = appendProtoElem(b, 5, encodeInnerInstructions(&meta.InnerInstructions[i]))
+	}
+	for _, msg := range meta.LogMessages {
+		b = appendProtoElem(b, 6, []byte(msg))
+	}
+	for i := range meta.PreTokenBalances {
+		b = appendProtoElem(b, 7, encodeTokenBalance(&meta.PreTokenBalances[i]))
+	}
+	for i := range meta.PostTokenBalances {
+		b = appendProtoElem(b, 8, encodeTokenBalance(&meta.PostTokenBalances[i]))
+	}
+	for i := range meta.Rewards {
+		b = appendProtoElem(b, 9, encodeReward(&meta.Rewards[i]))
+	}
+	if meta.InnerInstructions == nil {
+		b = appendProtoBool(b, 10, true)
+	}
+	if meta.LogMessages == nil {
+		b = appendProtoBool(b, 11, true)
+	}
+	for _, key := range meta.LoadedAddresses.Writable {
+		b = appendProtoElem(b, 12, key[:])
+	}
+	for _, key := range meta.LoadedAddresses.Readonly {
+		b = appendProtoElem(b, 13, key[:])
+	}
+	if meta.ReturnData != nil {
+		var rd []byte
+		rd = appendProtoBytes(rd, 1, meta.ReturnData.ProgramID[:])
+		rd = appendProtoBytes(rd, 2, meta.ReturnData.Data)
+		b = appendProtoElem(b, 14, rd)
+	} else {
+		b = appendProtoBool(b, 15, true)
+	}
+	if meta.ComputeUnitsConsumed != nil {
+		// Optional fields are emitted even if zero.
+		b = protowire.AppendTag(b, 16, protowire.VarintType)
+		b = protowire.AppendVarint(b, *meta.ComputeUnitsConsumed)
+	}
+	return b
+}
+
+// encodeInnerInstructions encodes a solana.storage.ConfirmedBlock.InnerInstructions.
+func encodeInnerInstructions(inner *InnerInstructions) []byte {
+	var b []byte
+	b = appendProtoVarint(b, 1, uint64(inner.Index))
+	for _, ins := range inner.Instructions {
+		var ib []byte
+		ib = appendProtoVarint(ib, 1, uint64(ins.ProgramIDIndex))
+		ib = appendProtoBytes(ib, 2, ins.Accounts)
+		ib = appendProtoBytes(ib, 3, ins.Data)
+		if ins.StackHeight != nil {
+			ib = protowire.AppendTag(ib, 4, protowire.VarintType)
+			ib = protowire.AppendVarint(ib, uint64(*ins.StackHeight))
+		}
+		b = appendProtoElem(b, 2, ib)
+	}
+	return b
+}
+
+// encodeTokenBalance encodes a solana.storage.ConfirmedBlock.TokenBalance.
+func encodeTokenBalance(balance *TokenBalance) []byte {
+	amount := &balance.UiTokenAmount
+	var ab []byte
+	if amount.UiAmount != 0 {
+		ab = protowire.AppendTag(ab, 1, protowire.Fixed64Type)
+		ab = protowire.AppendFixed64(ab, math.Float64bits(amount.UiAmount))
+	}
+	ab = appendProtoVarint(ab, 2, uint64(amount.Decimals))
+	ab = appendProtoString(ab, 3, amount.Amount)
+	ab = appendProtoString(ab, 4, amount.UiAmountString)
+
+	var b []byte
+	b = appendProtoVarint(b, 1, uint64(balance.AccountIndex))
+	b = appendProtoString(b, 2, balance.Mint.String())
+	b = appendProtoElem(b, 3, ab)
+	if !balance.Owner.IsZero() {
+		b = appendProtoString(b, 4, balance.Owner.String())
+	}
+	if !balance.ProgramID.IsZero() {
+		b = appendProtoString(b, 5, balance.ProgramID.String())
+	}
+	return b
+}
+
+// encodeReward encodes a solana.storage.ConfirmedBlock.Reward.
+func encodeReward(reward *Reward) []byte {
+	var b []byte
+	b = appendProtoString(b, 1, reward.Pubkey.String())
+	b = appendProtoVarint(b, 2, uint64(reward.Lamports))
+	b = appendProtoVarint(b, 3, reward.PostBalance)
+	b = appendProtoVarint(b, 4, uint64(reward.RewardType))
+	if reward.Commission != nil {
+		b = appendProtoString(b, 5, strconv.FormatUint(uint64(*reward.Commission), 10))
+	}
+	return b
+}
+
+// The appendProto helpers omit default values, like proto3 encoders do.
+// appendProtoElem always emits the field, as required for repeated elements and sub-messages.
+
+func appendProtoElem(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendProtoVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendProtoBool(b []byte, num protowire.Number, v bool) []byte {
+	return appendProtoVarint(b, num, protowire.EncodeBool(v))
+}
+
+func appendProtoBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendProtoString(b []byte, num protowire.Number, v string) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendProtoPackedUint64s(b []byte, num protowire.Number, list []uint64) []byte {
+	if len(list) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range list {
+		packed = protowire.AppendVarint(packed, v)
+	}
+	return appendProtoBytes(b, num, packed)
+}
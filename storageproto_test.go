@@ -0,0 +1,120 @@
+package blockstore
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// blockProtoFields returns the encoded transaction status metas
+// and rewards of a solana.storage.ConfirmedBlock.
+func blockProtoFields(t *testing.T, b []byte) (metas [][]byte, rewards [][]byte) {
+	t.Helper()
+	err := walkProto(b, func(f *protoField) error {
+		switch f.num {
+		case 4: // transactions
+			return walkProto(f.bytes, func(f *protoField) error {
+				if f.num == 2 { // meta
+					metas = append(metas, f.bytes)
+				}
+				return nil
+			})
+		case 5: // rewards
+			rewards = append(rewards, f.bytes)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestStorageProtoRoundTrip(t *testing.T) {
+	units := uint64(1234)
+	height := uint32(2)
+	commission := uint8(10)
+	rewards := []Reward{
+		{Pubkey: solana.PublicKey{2}, Lamports: 7, PostBalance: 8, RewardType: RewardTypeVoting, Commission: &commission},
+		{Pubkey: solana.PublicKey{4}, Lamports: -3, PostBalance: 1, RewardType: RewardTypeRent},
+	}
+	metas := []*TransactionStatusMeta{
+		{
+			// InstructionError(1, Custom(6001))
+			Err:          []byte{8, 0, 0, 0, 1, 25, 0, 0, 0, 0x71, 0x17, 0, 0},
+			Fee:          5000,
+			PreBalances:  []uint64{10, 0, 300},
+			PostBalances: []uint64{5, 0, 300},
+			InnerInstructions: []InnerInstructions{{
+				Index: 1,
+				Instructions: []InnerInstruction{
+					{ProgramIDIndex: 2, Accounts: []uint8{0, 1}, Data: []byte{1, 2, 3}, StackHeight: &height},
+					{ProgramIDIndex: 300, Accounts: []uint8{1}, Data: []byte{4}},
+				},
+			}},
+			LogMessages: []string{"log", "more log"},
+			PreTokenBalances: []TokenBalance{{
+				AccountIndex:  1,
+				Mint:          solana.PublicKey{1},
+				Owner:         solana.PublicKey{5},
+				ProgramID:     solana.PublicKey{6},
+				UiTokenAmount: UiTokenAmount{UiAmount: 1.5, Decimals: 6, Amount: "1500000", UiAmountString: "1.5"},
+			}},
+			PostTokenBalances: []TokenBalance{{
+				AccountIndex:  1,
+				Mint:          solana.PublicKey{1},
+				UiTokenAmount: UiTokenAmount{Decimals: 6, Amount: "0", UiAmountString: "0"},
+			}},
+			Rewards: rewards,
+			LoadedAddresses: LoadedAddresses{
+				Writable: []solana.PublicKey{{7}},
+				Readonly: []solana.PublicKey{{8}, {9}},
+			},
+			ReturnData:           &ReturnData{ProgramID: solana.PublicKey{3}, Data: []byte{0xff}},
+			ComputeUnitsConsumed: &units,
+		},
+		{
+			Fee:               5000,
+			PreBalances:       []uint64{10},
+			PostBalances:      []uint64{5},
+			InnerInstructions: []InnerInstructions{},
+			LogMessages:       []string{},
+		},
+	}
+
+	block := &ConfirmedBlock{Rewards: rewards}
+	for _, meta := range metas {
+		block.Transactions = append(block.Transactions, TransactionWithMeta{Meta: meta})
+	}
+	b, err := ToStorageProto(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotMetas, gotRewards := blockProtoFields(t, b)
+	if len(gotMetas) != len(metas) {
+		t.Fatalf("decoded %d metas, want %d", len(gotMetas), len(metas))
+	}
+	for i, want := range metas {
+		got, err := decodeTransactionStatusMeta(gotMetas[i])
+		if err != nil {
+			t.Fatalf("meta %d: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("meta %d: got %+v\nwant %+v", i, got, want)
+		}
+	}
+	if len(gotRewards) != len(rewards) {
+		t.Fatalf("decoded %d rewards, want %d", len(gotRewards), len(rewards))
+	}
+	for i, want := range rewards {
+		got, err := decodeReward(gotRewards[i])
+		if err != nil {
+			t.Fatalf("reward %d: %s", i, err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("reward %d: got %+v, want %+v", i, *got, want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package blockstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/linxGnu/grocksdb"
+)
+
+// BlockResult is the outcome of decoding one block in a stream.
+type BlockResult struct {
+	Slot  uint64
+	Block *Block
+	Err   error
+}
+
+// StreamBlocks decodes all rooted blocks in the slot range [start, end]
+// and sends them to the returned channel in ascending slot order.
+//
+// Errors of individual slots are delivered in BlockResult.Err
+// and do not abort the stream.
+// The channel is closed once the range is exhausted or ctx is cancelled.
+func (d *DB) StreamBlocks(ctx context.Context, start, end uint64) (<-chan BlockResult, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid slot range [%d, %d]", start, end)
+	}
+
+	out := make(chan BlockResult)
+	go func() {
+		defer close(out)
+		d.sendRootedBlocks(ctx, out, start, end)
+	}()
+	return out, nil
+}
+
+// sendRootedBlocks decodes the rooted blocks in the slot range [start, end] and sends them to out.
+//
+// Returns false if ctx was cancelled.
+func (d *DB) sendRootedBlocks(ctx context.Context, out chan<- BlockResult, start, end uint64) bool {
+	iter := d.db.NewIteratorCF(d.iterOpts, d.cfRoot)
+	defer iter.Close()
+
+	startKey := MakeSlotKey(start)
+	for iter.Seek(startKey[:]); iter.Valid(); iter.Next() {
+		res := BlockResult{}
+		res.Slot, res.Err = ParseSlotKey(iter.Key().Data())
+		if res.Err == nil {
+			if res.Slot > end {
+				break
+			}
+			res.Block, res.Err = d.GetBlock(res.Slot)
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case out <- res:
+		}
+	}
+	return true
+}
+
+// FollowPrimary periodically catches up a secondary DB with its primary,
+// invoking onCatchUp whenever the max root advances.
+//
+// Blocks until ctx is cancelled, in which case it returns nil,
+// or until catching up fails, in which case the error is returned.
+func (d *DB) FollowPrimary(ctx context.Context, interval time.Duration, onCatchUp func(newMaxRoot uint64)) error {
+	lastRoot, err := d.MaxRoot()
+	hasRoot := err == nil
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := d.TryCatchUpWithPrimary(); err != nil {
+			return fmt.Errorf("failed to catch up with primary: %w", err)
+		}
+		root, err := d.MaxRoot()
+		if errors.Is(err, ErrNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !hasRoot || root > lastRoot {
+			lastRoot, hasRoot = root, true
+			onCatchUp(root)
+		}
+	}
+}
+
+// SubscribeInterval is the interval at which SubscribeBlocks catches up with the primary,
+// roughly the duration of a slot.
+const SubscribeInterval = 400 * time.Millisecond
+
+// SubscribeBlocks follows the primary of a secondary DB
+// and sends each newly rooted block to the returned channel in ascending slot order.
+//
+// Blocks are delivered like in StreamBlocks, so slow consumers delay catching up.
+// If catching up fails, the error is sent as a BlockResult with a zero Slot
+// before the channel is closed.
+// The channel is also closed once ctx is cancelled.
+func (d *DB) SubscribeBlocks(ctx context.Context) (<-chan BlockResult, error) {
+	var next uint64
+	root, err := d.MaxRoot()
+	if err == nil {
+		next = root + 1
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	out := make(chan BlockResult)
+	go func() {
+		defer close(out)
+		// FollowPrimary returns once ctx is cancelled.
+		err := d.FollowPrimary(ctx, SubscribeInterval, func(newMaxRoot uint64) {
+			if newMaxRoot >= next && d.sendRootedBlocks(ctx, out, next, newMaxRoot) {
+				next = newMaxRoot + 1
+			}
+		})
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			case out <- BlockResult{Err: err}:
+			}
+		}
+	}()
+	return out, nil
+}
+
+// SlotSummary joins the state of a slot across column families.
+type SlotSummary struct {
+	Slot            uint64    `yaml:"slot" json:"slot"`
+	Meta            *SlotMeta `yaml:"meta" json:"meta"`
+	IsFull          bool      `yaml:"is_full" json:"is_full"`
+	IsRoot          bool      `yaml:"is_root" json:"is_root"`
+	IsDead          bool      `yaml:"is_dead" json:"is_dead"`
+	BlockTime       *int64    `yaml:"block_time" json:"block_time"` // nil if not recorded
+	NumDataShreds   uint64    `yaml:"num_data_shreds" json:"num_data_shreds"`
+	NumCodingShreds uint64    `yaml:"num_coding_shreds" json:"num_coding_shreds"`
+	Err             error     `yaml:"-" json:"-"`
+}
+
+// IterSlotSummaries summarizes every slot with a slot meta
+// and sends the summaries to the returned channel in ascending slot order.
+//
+// Each column family is scanned by a single iterator moving forward alongside CfMeta,
+// which is much cheaper than looking up each slot separately.
+// A summary with a non-nil Err ends the stream.
+// The channel is closed once all slots are visited or ctx is cancelled.
+func (d *DB) IterSlotSummaries(ctx context.Context) <-chan SlotSummary {
+	out := make(chan SlotSummary)
+	go func() {
+		defer close(out)
+
+		metas := d.IterSlotMetas(nil)
+		defer metas.Close()
+		roots := d.newSlotCursor(d.cfRoot)
+		defer roots.close()
+		dead := d.newSlotCursor(d.cfDeadSlots)
+		defer dead.close()
+		blockTimes := d.newSlotCursor(d.cfBlockTime)
+		defer blockTimes.close()
+		dataShreds := d.newSlotCursor(d.cfDataShred)
+		defer dataShreds.close()
+		codingShreds := d.newSlotCursor(d.cfCodeShred)
+		defer codingShreds.close()
+
+		for metas.SeekToFirst(); metas.Valid(); metas.Next() {
+			var summary SlotSummary
+			summary.Slot, summary.Meta, summary.Err = metas.SlotMeta()
+			if summary.Err == nil {
+				slot := summary.Slot
+				summary.IsFull = summary.Meta.IsFull()
+				summary.IsRoot = roots.has(slot)
+				if value, ok := dead.get(slot); ok {
+					summary.IsDead = bytes.Equal(value, []byte{1})
+				}
+				if value, ok := blockTimes.get(slot); ok && len(value) == 8 {
+					blockTime := int64(binary.LittleEndian.Uint64(value))
+					summary.BlockTime = &blockTime
+				}
+				summary.NumDataShreds = dataShreds.count(slot)
+				summary.NumCodingShreds = codingShreds.count(slot)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- summary:
+			}
+			if summary.Err != nil {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// slotCursor looks up keys prefixed with ascending slot numbers using a single iterator.
+//
+// The iterator is nil if the column family is unavailable.
+type slotCursor struct {
+	iter    *grocksdb.Iterator
+	limiter *readLimiter
+}
+
+func (d *DB) newSlotCursor(cf *grocksdb.ColumnFamilyHandle) slotCursor {
+	if cf == nil {
+		return slotCursor{}
+	}
+	return slotCursor{iter: d.db.NewIteratorCF(d.iterOpts, cf), limiter: d.limiter}
+}
+
+// seek moves to the first key of the slot and returns whether it exists.
+func (c slotCursor) seek(slot uint64) bool {
+	if c.iter == nil {
+		return false
+	}
+	prefix := MakeSlotKey(slot)
+	if !c.iter.Valid() || bytes.Compare(c.iter.Key().Data(), prefix[:]) < 0 {
+		c.iter.Seek(prefix[:])
+	}
+	return c.iter.Valid() && bytes.HasPrefix(c.iter.Key().Data(), prefix[:])
+}
+
+func (c slotCursor) has(slot uint64) bool {
+	return c.seek(slot)
+}
+
+// get returns the value of the first key of the slot.
+// The value is only valid until the cursor moves.
+func (c slotCursor) get(slot uint64) ([]byte, bool) {
+	if !c.seek(slot) {
+		return nil, false
+	}
+	value := c.iter.Value().Data()
+	c.limiter.charge(len(value))
+	return value, true
+}
+
+// count returns the number of keys of the slot, moving past them.
+func (c slotCursor) count(slot uint64) (n uint64) {
+	if !c.seek(slot) {
+		return 0
+	}
+	prefix := MakeSlotKey(slot)
+	for ; c.iter.Valid() && bytes.HasPrefix(c.iter.Key().Data(), prefix[:]); c.iter.Next() {
+		n++
+	}
+	return
+}
+
+func (c slotCursor) close() {
+	if c.iter != nil {
+		c.iter.Close()
+	}
+}
@@ -0,0 +1,248 @@
+// Package testutil fabricates blockstore ledgers for tests.
+package testutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/linxGnu/grocksdb"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/shred"
+)
+
+// Column families created in every ledger,
+// the minimum required by blockstore.OpenReadOnly.
+var columnFamilyNames = []string{
+	blockstore.CfDefault,
+	blockstore.CfMeta,
+	blockstore.CfRoot,
+	blockstore.CfDeadSlots,
+	blockstore.CfDataShred,
+	blockstore.CfCodeShred,
+}
+
+// Builder writes synthetic rows to a RocksDB ledger in a temporary directory.
+//
+// Call Path once done to obtain a ledger that blockstore.OpenReadOnly can read.
+// Failed writes abort the test.
+type Builder struct {
+	t    testing.TB
+	path string
+	db   *grocksdb.DB
+	cfs  map[string]*grocksdb.ColumnFamilyHandle
+	wo   *grocksdb.WriteOptions
+}
+
+// NewLedger creates an empty ledger in a temporary directory removed after the test.
+func NewLedger(t testing.TB) *Builder {
+	t.Helper()
+	path := t.TempDir()
+
+	opts := grocksdb.NewDefaultOptions()
+	defer opts.Destroy()
+	opts.SetCreateIfMissing(true)
+	opts.SetCreateIfMissingColumnFamilies(true)
+	cfOpts := make([]*grocksdb.Options, len(columnFamilyNames))
+	for i := range cfOpts {
+		cfOpts[i] = grocksdb.NewDefaultOptions()
+		defer cfOpts[i].Destroy()
+	}
+	db, handles, err := grocksdb.OpenDbColumnFamilies(opts, path, columnFamilyNames, cfOpts)
+	if err != nil {
+		t.Fatalf("cannot create ledger: %s", err)
+	}
+
+	b := &Builder{
+		t:    t,
+		path: path,
+		db:   db,
+		cfs:  make(map[string]*grocksdb.ColumnFamilyHandle, len(handles)),
+		wo:   grocksdb.NewDefaultWriteOptions(),
+	}
+	for i, name := range columnFamilyNames {
+		b.cfs[name] = handles[i]
+	}
+	t.Cleanup(b.close)
+	return b
+}
+
+// AddSlot writes the slot meta of meta.Slot.
+//
+// NumNextSlots and NumCompletedDataIndexes are derived from the slices.
+func (b *Builder) AddSlot(meta blockstore.SlotMeta) {
+	b.t.Helper()
+	meta.NumNextSlots = uint64(len(meta.NextSlots))
+	meta.NumCompletedDataIndexes = uint64(len(meta.CompletedDataIndexes))
+	var buf bytes.Buffer
+	if err := bin.NewBinEncoder(&buf).Encode(&meta); err != nil {
+		b.t.Fatalf("cannot encode slot meta %d: %s", meta.Slot, err)
+	}
+	key := blockstore.MakeSlotKey(meta.Slot)
+	b.put(blockstore.CfMeta, key[:], buf.Bytes())
+}
+
+// AddShred writes the payload of a data shred.
+func (b *Builder) AddShred(slot, index uint64, payload []byte) {
+	b.t.Helper()
+	key := blockstore.MakeShredKey(slot, index)
+	b.put(blockstore.CfDataShred, key[:], payload)
+}
+
+// AddCodingShred writes the payload of a coding shred.
+func (b *Builder) AddCodingShred(slot, index uint64, payload []byte) {
+	b.t.Helper()
+	key := blockstore.MakeShredKey(slot, index)
+	b.put(blockstore.CfCodeShred, key[:], payload)
+}
+
+// AddBlock writes a full slot holding the given entries as a single data set of legacy data shreds,
+// along with its slot meta. Returns the number of data shreds written.
+//
+// The slot is not rooted, see AddRoot.
+func (b *Builder) AddBlock(slot, parentSlot uint64, entries []blockstore.Entry) int {
+	b.t.Helper()
+	shreds := LegacyDataShreds(slot, parentSlot, EncodeEntries(b.t, entries))
+	for i, payload := range shreds {
+		b.AddShred(slot, uint64(i), payload)
+	}
+	b.AddSlot(FullSlotMeta(slot, parentSlot, len(shreds)))
+	return len(shreds)
+}
+
+// AddRoot marks a slot as rooted.
+func (b *Builder) AddRoot(slot uint64) {
+	b.t.Helper()
+	key := blockstore.MakeSlotKey(slot)
+	b.put(blockstore.CfRoot, key[:], []byte{1})
+}
+
+// AddDeadSlot marks a slot as dead.
+func (b *Builder) AddDeadSlot(slot uint64) {
+	b.t.Helper()
+	key := blockstore.MakeSlotKey(slot)
+	b.put(blockstore.CfDeadSlots, key[:], []byte{1})
+}
+
+func (b *Builder) put(cf string, key, value []byte) {
+	b.t.Helper()
+	if b.db == nil {
+		b.t.Fatal("ledger already finished")
+	}
+	if err := b.db.PutCF(b.wo, b.cfs[cf], key, value); err != nil {
+		b.t.Fatalf("cannot write to %s: %s", cf, err)
+	}
+}
+
+// Path flushes and closes the ledger, returning its directory.
+//
+// No rows can be added afterwards.
+func (b *Builder) Path() string {
+	b.t.Helper()
+	if b.db != nil {
+		fo := grocksdb.NewDefaultFlushOptions()
+		defer fo.Destroy()
+		fo.SetWait(true)
+		for name, cf := range b.cfs {
+			if err := b.db.FlushCF(cf, fo); err != nil {
+				b.t.Fatalf("cannot flush %s: %s", name, err)
+			}
+		}
+		b.close()
+	}
+	return b.path
+}
+
+func (b *Builder) close() {
+	if b.db == nil {
+		return
+	}
+	for _, cf := range b.cfs {
+		cf.Destroy()
+	}
+	b.db.Close()
+	b.db = nil
+	b.wo.Destroy()
+}
+
+// FullSlotMeta returns the slot meta of a connected slot whose numShreds data shreds
+// were all received and form a single data set.
+func FullSlotMeta(slot, parentSlot uint64, numShreds int) blockstore.SlotMeta {
+	n := uint64(numShreds)
+	return blockstore.SlotMeta{
+		Slot:                 slot,
+		Consumed:             n,
+		Received:             n,
+		LastIndex:            n - 1,
+		ParentSlot:           parentSlot,
+		IsConnected:          true,
+		CompletedDataIndexes: []uint32{uint32(n - 1)},
+	}
+}
+
+// EncodeEntries serializes entries into the payload of a data set,
+// as deshredded by blockstore.DB.GetEntriesInDataBlock.
+func EncodeEntries(t testing.TB, entries []blockstore.Entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := bin.NewBinEncoder(&buf)
+	if err := enc.WriteUint64(uint64(len(entries)), bin.LE); err != nil {
+		t.Fatalf("cannot encode entries: %s", err)
+	}
+	for i := range entries {
+		entry := entries[i]
+		entry.NumTxns = uint64(len(entry.Transactions))
+		if err := enc.Encode(&entry); err != nil {
+			t.Fatalf("cannot encode entry %d: %s", i, err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// LegacyDataShreds splits the payload of a data set into legacy data shreds of a slot,
+// starting at index 0. The last shred is flagged as the last in the slot.
+//
+// Shreds are zero-padded to shred.LegacyPayloadSize, see TrimLegacyPadding.
+// Signatures are left zero.
+func LegacyDataShreds(slot, parentSlot uint64, payload []byte) [][]byte {
+	const capacity = shred.LegacyPayloadSize - shred.LegacyHeaderSize
+	var shreds [][]byte
+	for i := 0; i == 0 || len(payload) > 0; i++ {
+		n := len(payload)
+		if n > capacity {
+			n = capacity
+		}
+		var flags uint8
+		if n == len(payload) {
+			flags = shred.FlagLastShredInSlot
+		}
+		common := shred.CommonHeader{
+			Variant: shred.LegacyDataID,
+			Slot:    slot,
+			Index:   uint32(i),
+		}
+		header := shred.DataHeader{
+			ParentOffset: uint16(slot - parentSlot),
+			Flags:        flags,
+			Size:         uint16(shred.LegacyHeaderSize + n),
+		}
+		var buf bytes.Buffer
+		enc := bin.NewBinEncoder(&buf)
+		_ = enc.Encode(&common)
+		_ = enc.Encode(&header)
+		buf.Write(payload[:n])
+		out := make([]byte, shred.LegacyPayloadSize)
+		copy(out, buf.Bytes())
+		shreds = append(shreds, out)
+		payload = payload[n:]
+	}
+	return shreds
+}
+
+// TrimLegacyPadding strips the zero padding of a legacy data shred,
+// as stored by older validators.
+func TrimLegacyPadding(payload []byte) []byte {
+	size := int(binary.LittleEndian.Uint16(payload[shred.LegacyHeaderSize-2:]))
+	return payload[:size]
+}
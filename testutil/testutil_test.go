@@ -0,0 +1,93 @@
+package testutil_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+func testEntries() []blockstore.Entry {
+	tx := blockstore.Transaction{
+		Transaction: solana.Transaction{
+			Signatures: []solana.Signature{{1}},
+			Message: solana.Message{
+				AccountKeys: []solana.PublicKey{{2}, {3}},
+				Header: solana.MessageHeader{
+					NumRequiredSignatures:       1,
+					NumReadonlyUnsignedAccounts: 1,
+				},
+				RecentBlockhash: solana.Hash{4},
+				Instructions: []solana.CompiledInstruction{
+					{ProgramIDIndex: 1, Accounts: []uint16{0}, Data: []byte{5, 6}},
+				},
+			},
+		},
+	}
+	return []blockstore.Entry{
+		{NumHashes: 1, Hash: solana.Hash{7}},
+		{NumHashes: 1, Hash: solana.Hash{8}, Transactions: []blockstore.Transaction{tx}},
+	}
+}
+
+func TestNewLedger(t *testing.T) {
+	b := testutil.NewLedger(t)
+	b.AddBlock(10, 9, testEntries())
+	b.AddRoot(10)
+	db, err := blockstore.OpenReadOnly(b.Path())
+	if err != nil {
+		t.Fatalf("cannot open ledger: %s", err)
+	}
+	defer db.Close()
+
+	meta, err := db.GetSlotMeta(10)
+	if err != nil {
+		t.Fatalf("GetSlotMeta: %s", err)
+	}
+	if meta.Slot != 10 || meta.ParentSlot != 9 || !meta.IsFull() {
+		t.Errorf("unexpected slot meta: %+v", meta)
+	}
+	if isRoot, err := db.IsRoot(10); err != nil || !isRoot {
+		t.Errorf("IsRoot(10) = %v, %v", isRoot, err)
+	}
+
+	block, err := db.GetBlock(10)
+	if err != nil {
+		t.Fatalf("GetBlock: %s", err)
+	}
+	if block.BlockHash != (solana.Hash{8}) {
+		t.Errorf("block hash %s", block.BlockHash)
+	}
+	if block.ParentSlot != 9 {
+		t.Errorf("parent slot %d", block.ParentSlot)
+	}
+	if len(block.Transactions) != 1 {
+		t.Fatalf("got %d transactions", len(block.Transactions))
+	}
+	tx := block.Transactions[0]
+	if len(tx.Signatures) != 1 || tx.Signatures[0] != (solana.Signature{1}) {
+		t.Errorf("signatures %v", tx.Signatures)
+	}
+	if tx.Message.RecentBlockhash != (solana.Hash{4}) {
+		t.Errorf("recent blockhash %s", tx.Message.RecentBlockhash)
+	}
+	if ins := tx.Message.Instructions; len(ins) != 1 || string(ins[0].Data) != "\x05\x06" {
+		t.Errorf("instructions %+v", ins)
+	}
+}
+
+func TestLegacyDataShreds(t *testing.T) {
+	payload := make([]byte, 3000)
+	shreds := testutil.LegacyDataShreds(5, 4, payload)
+	if len(shreds) != 3 {
+		t.Fatalf("got %d shreds", len(shreds))
+	}
+	last := testutil.TrimLegacyPadding(shreds[2])
+	if want := 88 + 3000 - 2*1140; len(last) != want {
+		t.Errorf("trimmed last shred to %d bytes, want %d", len(last), want)
+	}
+	if n := len(testutil.TrimLegacyPadding(shreds[0])); n != 1228 {
+		t.Errorf("trimmed first shred to %d bytes", n)
+	}
+}
@@ -0,0 +1,39 @@
+package blockstore
+
+import "context"
+
+// Tracer starts spans around expensive operations,
+// such as GetBlockContext, GetSlotEntriesContext, and erasure recovery.
+//
+// It is a minimal subset of OpenTelemetry's tracing API,
+// so that adapters can be written without this package depending on it.
+type Tracer interface {
+	// Start creates a span and a context containing it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a traced operation started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value any)
+	// End completes the span, recording err if not nil.
+	End(err error)
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, any) {}
+
+func (noopSpan) End(error) {}
+
+// startSpan starts a span of an operation on a slot.
+func (d *DB) startSpan(ctx context.Context, name string, slot uint64) (context.Context, Span) {
+	ctx, span := d.tracer.Start(ctx, name)
+	span.SetAttribute("slot", slot)
+	return ctx, span
+}
@@ -0,0 +1,222 @@
+package blockstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Number of primary indexes in CfTransactionStatus.
+//
+// The validator alternates between two primary indexes to allow cheap purges.
+const numTxStatusPrimaryIndexes = 2
+
+// MakeTransactionStatusKey creates the RocksDB key for CfTransactionStatus.
+func MakeTransactionStatusKey(primaryIndex uint64, sig solana.Signature, slot uint64) (key [80]byte) {
+	binary.BigEndian.PutUint64(key[0:8], primaryIndex)
+	copy(key[8:72], sig[:])
+	binary.BigEndian.PutUint64(key[72:80], slot)
+	return
+}
+
+// GetTransactionStatusIndex returns the metadata of a CfTransactionStatus primary index.
+func (d *DB) GetTransactionStatusIndex(primaryIndex uint64) (*TransactionStatusIndexMeta, error) {
+	key := MakeSlotKey(primaryIndex)
+	return getBincode[TransactionStatusIndexMeta](d.db, d.readOpts, d.limiter, d.cfTxStatusIdx, key[:])
+}
+
+// GetTransactionStatus returns the status meta of a transaction in the given slot.
+func (d *DB) GetTransactionStatus(slot uint64, sig solana.Signature) (*TransactionStatusMeta, error) {
+	if err := requireCF(d.cfTxStatus, CfTransactionStatus); err != nil {
+		return nil, err
+	}
+	opts := d.readOpts
+	for i := uint64(0); i < numTxStatusPrimaryIndexes; i++ {
+		key := MakeTransactionStatusKey(i, sig, slot)
+		res, err := d.getCF(opts, d.cfTxStatus, key[:])
+		if err != nil {
+			return nil, err
+		}
+		if !res.Exists() {
+			res.Free()
+			continue
+		}
+		meta, err := decodeTransactionStatusMeta(res.Data())
+		res.Free()
+		if err != nil {
+			return nil, fmt.Errorf("invalid status meta of tx %s: %w", sig, err)
+		}
+		return meta, nil
+	}
+	return nil, ErrNotFound
+}
+
+// GetTransactionSlots returns all slots containing a transaction with the given signature.
+//
+// A signature may appear in multiple slots if the transaction landed on several forks.
+func (d *DB) GetTransactionSlots(sig solana.Signature) ([]uint64, error) {
+	if err := requireCF(d.cfTxStatus, CfTransactionStatus); err != nil {
+		return nil, err
+	}
+	var slots []uint64
+	for i := uint64(0); i < numTxStatusPrimaryIndexes; i++ {
+		_, err := d.GetTransactionStatusIndex(i)
+		if errors.Is(err, ErrNotFound) {
+			continue // primary index not in use
+		} else if err != nil {
+			return nil, err
+		}
+
+		prefix := MakeTransactionStatusKey(i, sig, 0)
+		iter := d.db.NewIteratorCF(d.iterOpts, d.cfTxStatus)
+		for iter.Seek(prefix[:]); iter.Valid(); iter.Next() {
+			key := iter.Key().Data()
+			if len(key) != len(prefix) || !bytes.Equal(key[:72], prefix[:72]) {
+				break
+			}
+			slots = append(slots, binary.BigEndian.Uint64(key[72:80]))
+		}
+		iter.Close()
+	}
+	return slots, nil
+}
+
+// GetTransaction looks up a rooted transaction by its signature.
+//
+// If the signature appears in multiple slots, the rooted one is returned.
+func (d *DB) GetTransaction(sig solana.Signature) (*ConfirmedTransaction, error) {
+	slots, err := d.GetTransactionSlots(sig)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		isRoot, err := d.IsRoot(slot)
+		if err != nil {
+			return nil, err
+		}
+		if !isRoot {
+			continue
+		}
+
+		block, err := d.GetBlock(slot)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range block.Transactions {
+			if len(tx.Signatures) == 0 || tx.Signatures[0] != sig {
+				continue
+			}
+			meta, err := d.GetTransactionStatus(slot, sig)
+			if err != nil {
+				return nil, err
+			}
+			return &ConfirmedTransaction{
+				Slot:        slot,
+				Transaction: tx,
+				Meta:        meta,
+			}, nil
+		}
+		return nil, fmt.Errorf("tx %s missing from block %d", sig, slot)
+	}
+	return nil, ErrNotFound
+}
+
+// GetTransactionMemos returns the SPL memos attached to a transaction.
+//
+// Memos of all memo instructions are concatenated by the validator.
+func (d *DB) GetTransactionMemos(sig solana.Signature) (string, error) {
+	if err := requireCF(d.cfTxMemos, CfTransactionMemos); err != nil {
+		return "", err
+	}
+	// Newer validators append the slot to the key, so seek by signature prefix.
+	iter := d.db.NewIteratorCF(d.iterOpts, d.cfTxMemos)
+	defer iter.Close()
+	iter.Seek(sig[:])
+	if !iter.Valid() || !bytes.HasPrefix(iter.Key().Data(), sig[:]) {
+		return "", ErrNotFound
+	}
+	value := iter.Value().Data()
+	d.limiter.charge(len(value))
+	memos, err := ParseBincode[string](value)
+	if err != nil {
+		return "", err
+	}
+	return *memos, nil
+}
+
+// MakeAddressSignatureKey creates the RocksDB key for CfAddressSignatures.
+func MakeAddressSignatureKey(primaryIndex uint64, address solana.PublicKey, slot uint64, sig solana.Signature) (key [112]byte) {
+	binary.BigEndian.PutUint64(key[0:8], primaryIndex)
+	copy(key[8:40], address[:])
+	binary.BigEndian.PutUint64(key[40:48], slot)
+	copy(key[48:112], sig[:])
+	return
+}
+
+// GetSignaturesForAddress returns up to `limit` signatures of rooted
+// transactions that loaded the given address, newest slot first.
+//
+// Returns an error if limit is negative.
+func (d *DB) GetSignaturesForAddress(address solana.PublicKey, limit int) ([]AddressSignature, error) {
+	if limit < 0 {
+		return nil, fmt.Errorf("negative limit %d", limit)
+	}
+	if err := requireCF(d.cfAddrSigs, CfAddressSignatures); err != nil {
+		return nil, err
+	}
+	var sigs []AddressSignature
+	for i := uint64(0); i < numTxStatusPrimaryIndexes; i++ {
+		indexSigs, err := d.getSignaturesForAddress(i, address, limit)
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, indexSigs...)
+	}
+	sort.SliceStable(sigs, func(i, j int) bool {
+		return sigs[i].Slot > sigs[j].Slot
+	})
+	if len(sigs) > limit {
+		sigs = sigs[:limit]
+	}
+	return sigs, nil
+}
+
+func (d *DB) getSignaturesForAddress(primaryIndex uint64, address solana.PublicKey, limit int) ([]AddressSignature, error) {
+	var maxSig solana.Signature
+	for i := range maxSig {
+		maxSig[i] = 0xFF
+	}
+	last := MakeAddressSignatureKey(primaryIndex, address, math.MaxUint64, maxSig)
+	prefix := last[:40]
+
+	iter := d.db.NewIteratorCF(d.iterOpts, d.cfAddrSigs)
+	defer iter.Close()
+	var sigs []AddressSignature
+	for iter.SeekForPrev(last[:]); iter.Valid() && len(sigs) < limit; iter.Prev() {
+		key := iter.Key().Data()
+		if len(key) != len(last) || !bytes.Equal(key[:40], prefix) {
+			break
+		}
+		value := iter.Value().Data()
+		d.limiter.charge(len(value))
+		sig := AddressSignature{
+			Slot:      binary.BigEndian.Uint64(key[40:48]),
+			Writeable: bytes.Equal(value, []byte{1}),
+		}
+		copy(sig.Signature[:], key[48:112])
+
+		isRoot, err := d.IsRoot(sig.Slot)
+		if err != nil {
+			return nil, err
+		}
+		if isRoot {
+			sigs = append(sigs, sig)
+		}
+	}
+	return sigs, nil
+}
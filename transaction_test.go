@@ -0,0 +1,20 @@
+package blockstore_test
+
+import (
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	blockstore "github.com/terorie/solana-blockstore-go"
+	"github.com/terorie/solana-blockstore-go/testutil"
+)
+
+func TestGetSignaturesForAddressNegativeLimit(t *testing.T) {
+	db, err := blockstore.OpenReadOnly(testutil.NewLedger(t).Path())
+	if err != nil {
+		t.Fatalf("cannot open ledger: %s", err)
+	}
+	defer db.Close()
+	if _, err := db.GetSignaturesForAddress(solana.PublicKey{1}, -1); err == nil {
+		t.Error("accepted negative limit")
+	}
+}
@@ -0,0 +1,192 @@
+package blockstore
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+)
+
+// Variants of solana_sdk::transaction::TransactionError, by bincode discriminant.
+var transactionErrorNames = []string{
+	"AccountInUse",
+	"AccountLoadedTwice",
+	"AccountNotFound",
+	"ProgramAccountNotFound",
+	"InsufficientFundsForFee",
+	"InvalidAccountForFee",
+	"AlreadyProcessed",
+	"BlockhashNotFound",
+	"InstructionError",
+	"CallChainTooDeep",
+	"MissingSignatureForFee",
+	"InvalidAccountIndex",
+	"SignatureFailure",
+	"InvalidProgramForExecution",
+	"SanitizeFailure",
+	"ClusterMaintenance",
+	"AccountBorrowOutstanding",
+	"WouldExceedMaxBlockCostLimit",
+	"UnsupportedVersion",
+	"InvalidWritableAccount",
+	"WouldExceedMaxAccountCostLimit",
+	"WouldExceedAccountDataBlockLimit",
+	"TooManyAccountLocks",
+	"AddressLookupTableNotFound",
+	"InvalidAddressLookupTableOwner",
+	"InvalidAddressLookupTableData",
+	"InvalidAddressLookupTableIndex",
+	"InvalidRentPayingAccount",
+	"WouldExceedMaxVoteCostLimit",
+	"WouldExceedAccountDataTotalLimit",
+	"DuplicateInstruction",
+	"InsufficientFundsForRent",
+	"MaxLoadedAccountsDataSizeExceeded",
+	"InvalidLoadedAccountsDataSizeLimit",
+	"ResanitizationNeeded",
+	"ProgramExecutionTemporarilyRestricted",
+	"UnbalancedTransaction",
+	"ProgramCacheHitMaxLimit",
+	"CommitCancelled",
+}
+
+// Variants of solana_sdk::instruction::InstructionError, by bincode discriminant.
+var instructionErrorNames = []string{
+	"GenericError",
+	"InvalidArgument",
+	"InvalidInstructionData",
+	"InvalidAccountData",
+	"AccountDataTooSmall",
+	"InsufficientFunds",
+	"IncorrectProgramId",
+	"MissingRequiredSignature",
+	"AccountAlreadyInitialized",
+	"UninitializedAccount",
+	"UnbalancedInstruction",
+	"ModifiedProgramId",
+	"ExternalAccountLamportSpend",
+	"ExternalAccountDataModified",
+	"ReadonlyLamportChange",
+	"ReadonlyDataModified",
+	"DuplicateAccountIndex",
+	"ExecutableModified",
+	"RentEpochModified",
+	"NotEnoughAccountKeys",
+	"AccountDataSizeChanged",
+	"AccountNotExecutable",
+	"AccountBorrowFailed",
+	"AccountBorrowOutstanding",
+	"DuplicateAccountOutOfSync",
+	"Custom",
+	"InvalidError",
+	"ExecutableDataModified",
+	"ExecutableLamportChange",
+	"ExecutableAccountNotRentExempt",
+	"UnsupportedProgramId",
+	"CallDepth",
+	"MissingAccount",
+	"ReentrancyNotAllowed",
+	"MaxSeedLengthExceeded",
+	"InvalidSeeds",
+	"InvalidRealloc",
+	"ComputationalBudgetExceeded",
+	"PrivilegeEscalation",
+	"ProgramEnvironmentSetupFailure",
+	"ProgramFailedToComplete",
+	"ProgramFailedToCompile",
+	"Immutable",
+	"IncorrectAuthority",
+	"BorshIoError",
+	"AccountNotRentExempt",
+	"InvalidAccountOwner",
+	"ArithmeticOverflow",
+	"UnsupportedSysvar",
+	"IllegalOwner",
+	"MaxAccountsDataAllocationsExceeded",
+	"MaxAccountsExceeded",
+	"MaxInstructionTraceLengthExceeded",
+	"BuiltinProgramsMustConsumeComputeUnits",
+}
+
+// transactionErrorJSON converts a bincode TransactionError to the value of its JSON-RPC encoding,
+// e.g. "AccountInUse" or {"InstructionError": [0, {"Custom": 1}]}.
+//
+// Errors unknown to this package are returned as hex-encoded bincode.
+func transactionErrorJSON(b []byte) any {
+	v, err := decodeTransactionError(bin.NewBinDecoder(b))
+	if err != nil {
+		return hex.EncodeToString(b)
+	}
+	return v
+}
+
+func decodeTransactionError(dec *bin.Decoder) (any, error) {
+	variant, err := dec.ReadUint32(bin.LE)
+	if err != nil {
+		return nil, err
+	}
+	if int(variant) >= len(transactionErrorNames) {
+		return nil, fmt.Errorf("unknown transaction error %d", variant)
+	}
+	name := transactionErrorNames[variant]
+	switch name {
+	case "InstructionError":
+		index, err := dec.ReadUint8()
+		if err != nil {
+			return nil, err
+		}
+		insErr, err := decodeInstructionError(dec)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{name: []any{index, insErr}}, nil
+	case "DuplicateInstruction":
+		index, err := dec.ReadUint8()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{name: index}, nil
+	case "InsufficientFundsForRent", "ProgramExecutionTemporarilyRestricted":
+		index, err := dec.ReadUint8()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{name: map[string]any{"account_index": index}}, nil
+	default:
+		return name, nil
+	}
+}
+
+func decodeInstructionError(dec *bin.Decoder) (any, error) {
+	variant, err := dec.ReadUint32(bin.LE)
+	if err != nil {
+		return nil, err
+	}
+	if int(variant) >= len(instructionErrorNames) {
+		return nil, fmt.Errorf("unknown instruction error %d", variant)
+	}
+	name := instructionErrorNames[variant]
+	switch name {
+	case "Custom":
+		code, err := dec.ReadUint32(bin.LE)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{name: code}, nil
+	case "BorshIoError":
+		n, err := dec.ReadUint64(bin.LE)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(dec.Remaining()) {
+			return nil, fmt.Errorf("borsh error message of length %d", n)
+		}
+		msg, err := dec.ReadNBytes(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{name: string(msg)}, nil
+	default:
+		return name, nil
+	}
+}
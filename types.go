@@ -1,23 +1,29 @@
 package blockstore
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
 	"math"
 
+	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
+	"github.com/terorie/solana-blockstore-go/shred"
 )
 
 type SlotMeta struct {
-	Slot                    uint64   `yaml:"-"`
-	Consumed                uint64   `yaml:"consumed"`
-	Received                uint64   `yaml:"received"`
-	FirstShredTimestamp     uint64   `yaml:"first_shred_timestamp"`
-	LastIndex               uint64   `yaml:"last_index"`  // optional, None being math.MaxUint64
-	ParentSlot              uint64   `yaml:"parent_slot"` // optional, None being math.MaxUint64
-	NumNextSlots            uint64   `bin:"sizeof=NextSlots" yaml:"-"`
-	NextSlots               []uint64 `yaml:"next_slots"`
-	IsConnected             bool     `yaml:"is_connected"`
-	NumCompletedDataIndexes uint64   `bin:"sizeof=CompletedDataIndexes" yaml:"-"`
-	CompletedDataIndexes    []uint32 `yaml:"completed_data_indexes"`
+	Slot                    uint64   `yaml:"slot" json:"slot"`
+	Consumed                uint64   `yaml:"consumed" json:"consumed"`
+	Received                uint64   `yaml:"received" json:"received"`
+	FirstShredTimestamp     uint64   `yaml:"first_shred_timestamp" json:"first_shred_timestamp"`
+	LastIndex               uint64   `yaml:"last_index" json:"last_index"`   // optional, None being math.MaxUint64
+	ParentSlot              uint64   `yaml:"parent_slot" json:"parent_slot"` // optional, None being math.MaxUint64
+	NumNextSlots            uint64   `bin:"sizeof=NextSlots" yaml:"-" json:"-"`
+	NextSlots               []uint64 `yaml:"next_slots" json:"next_slots"`
+	IsConnected             bool     `yaml:"is_connected" json:"is_connected"`
+	NumCompletedDataIndexes uint64   `bin:"sizeof=CompletedDataIndexes" yaml:"-" json:"-"`
+	CompletedDataIndexes    []uint32 `yaml:"completed_data_indexes" json:"completed_data_indexes"`
 }
 
 func (s *SlotMeta) IsFull() bool {
@@ -29,10 +35,143 @@ func (s *SlotMeta) IsFull() bool {
 	return s.Consumed == s.LastIndex+1
 }
 
+// LastIndexOpt returns the index of the last shred in the slot,
+// or nil if it is not yet known.
+func (s *SlotMeta) LastIndexOpt() *uint64 {
+	return optionalSlot(s.LastIndex)
+}
+
+// ParentSlotOpt returns the parent slot, or nil if it is not yet known.
+func (s *SlotMeta) ParentSlotOpt() *uint64 {
+	return optionalSlot(s.ParentSlot)
+}
+
+// optionalSlot converts Rust's Option<u64> encoded as math.MaxUint64 for None.
+func optionalSlot(v uint64) *uint64 {
+	if v == math.MaxUint64 {
+		return nil
+	}
+	return &v
+}
+
+// IsConnectedFull returns whether the slot is full and chains to a full parent.
+func (s *SlotMeta) IsConnectedFull() bool {
+	return s.IsConnected && s.IsFull()
+}
+
 type Block struct {
-	BlockHash    solana.Hash
-	ParentSlot   uint64
-	Transactions []solana.Transaction
+	BlockHash         solana.Hash   `yaml:"block_hash" json:"block_hash"`
+	PreviousBlockHash solana.Hash   `yaml:"previous_block_hash" json:"previous_block_hash"` // zero for the genesis block or if the parent was purged
+	ParentSlot        uint64        `yaml:"parent_slot" json:"parent_slot"`
+	Transactions      []Transaction `yaml:"transactions" json:"transactions"`
+}
+
+// Signatures returns the first signature of each transaction in the block,
+// which identifies the transaction.
+// Transactions without signatures are skipped.
+func (b *Block) Signatures() []solana.Signature {
+	return appendSignatures(nil, b.Transactions)
+}
+
+// IndexedTransactions returns the transactions of the block along with their index,
+// which equals their position in Transactions.
+func (b *Block) IndexedTransactions() []IndexedTransaction {
+	txns := make([]IndexedTransaction, len(b.Transactions))
+	for i := range b.Transactions {
+		txns[i] = IndexedTransaction{Index: i, Tx: b.Transactions[i]}
+	}
+	return txns
+}
+
+// CanonicalHash returns a digest of the block contents,
+// e.g. to check whether two ledger copies agree on a slot.
+//
+// The digest is the SHA-256 hash of, in order:
+// BlockHash, ParentSlot as a little-endian uint64,
+// the number of transactions as a little-endian uint64,
+// and for each transaction in block order,
+// the number of its signatures as a little-endian uint64 followed by the signatures.
+func (b *Block) CanonicalHash() [32]byte {
+	h := sha256.New()
+	var num [8]byte
+	h.Write(b.BlockHash[:])
+	binary.LittleEndian.PutUint64(num[:], b.ParentSlot)
+	h.Write(num[:])
+	binary.LittleEndian.PutUint64(num[:], uint64(len(b.Transactions)))
+	h.Write(num[:])
+	for i := range b.Transactions {
+		sigs := b.Transactions[i].Signatures
+		binary.LittleEndian.PutUint64(num[:], uint64(len(sigs)))
+		h.Write(num[:])
+		for _, sig := range sigs {
+			h.Write(sig[:])
+		}
+	}
+	var digest [32]byte
+	h.Sum(digest[:0])
+	return digest
+}
+
+// MarshalJSON encodes the block with the instruction data of its transactions in base64,
+// instead of the base58 encoding used by solana-go.
+// Signatures, public keys and hashes remain base58.
+func (b Block) MarshalJSON() ([]byte, error) {
+	type blockJSON struct {
+		BlockHash         solana.Hash       `json:"block_hash"`
+		PreviousBlockHash solana.Hash       `json:"previous_block_hash"`
+		ParentSlot        uint64            `json:"parent_slot"`
+		Transactions      []transactionJSON `json:"transactions"`
+	}
+	txns := make([]transactionJSON, len(b.Transactions))
+	for i := range b.Transactions {
+		txns[i] = newTransactionJSON(&b.Transactions[i])
+	}
+	return json.Marshal(blockJSON{
+		BlockHash:         b.BlockHash,
+		PreviousBlockHash: b.PreviousBlockHash,
+		ParentSlot:        b.ParentSlot,
+		Transactions:      txns,
+	})
+}
+
+// transactionJSON mirrors the JSON encoding of Transaction,
+// except for instruction data being []byte and thus base64-encoded.
+type transactionJSON struct {
+	Signatures []solana.Signature `json:"signatures"`
+	Message    struct {
+		AccountKeys     []solana.PublicKey   `json:"accountKeys"`
+		Header          solana.MessageHeader `json:"header"`
+		RecentBlockhash solana.Hash          `json:"recentBlockhash"`
+		Instructions    []instructionJSON    `json:"instructions"`
+	} `json:"message"`
+	Versioned           bool                        `json:"versioned"`
+	AddressTableLookups []MessageAddressTableLookup `json:"addressTableLookups,omitempty"`
+	LoadedAddresses     *LoadedAddresses            `json:"loadedAddresses,omitempty"`
+}
+
+type instructionJSON struct {
+	ProgramIDIndex uint16   `json:"programIdIndex"`
+	Accounts       []uint16 `json:"accounts"`
+	Data           []byte   `json:"data"`
+}
+
+func newTransactionJSON(tx *Transaction) (out transactionJSON) {
+	out.Signatures = tx.Signatures
+	out.Message.AccountKeys = tx.Message.AccountKeys
+	out.Message.Header = tx.Message.Header
+	out.Message.RecentBlockhash = tx.Message.RecentBlockhash
+	out.Message.Instructions = make([]instructionJSON, len(tx.Message.Instructions))
+	for i, ins := range tx.Message.Instructions {
+		out.Message.Instructions[i] = instructionJSON{
+			ProgramIDIndex: ins.ProgramIDIndex,
+			Accounts:       ins.Accounts,
+			Data:           ins.Data,
+		}
+	}
+	out.Versioned = tx.Versioned
+	out.AddressTableLookups = tx.AddressTableLookups
+	out.LoadedAddresses = tx.LoadedAddresses
+	return
 }
 
 type CompletedRange struct {
@@ -40,9 +179,255 @@ type CompletedRange struct {
 	EndIndex   uint32
 }
 
+// DataBlockEntries are the entries decoded from a completed range of data shreds.
+type DataBlockEntries struct {
+	Range   CompletedRange
+	Entries []Entry
+}
+
+// CompletedDataSetInfo identifies a completed data set,
+// the range of data shreds [StartIndex, EndIndex] holding one entry vector.
+type CompletedDataSetInfo struct {
+	Slot       uint64 `yaml:"slot" json:"slot"`
+	StartIndex uint32 `yaml:"start_index" json:"start_index"`
+	EndIndex   uint32 `yaml:"end_index" json:"end_index"`
+	LastInSlot bool   `yaml:"last_in_slot" json:"last_in_slot"`
+}
+
 type Entry struct {
-	NumHashes    uint64               `yaml:"num_hashes"`
-	Hash         solana.Hash          `yaml:"hash"`
-	NumTxns      uint64               `bin:"sizeof=Transactions" yaml:"-"`
-	Transactions []solana.Transaction `yaml:"transactions"`
+	NumHashes    uint64        `yaml:"num_hashes" json:"num_hashes"`
+	Hash         solana.Hash   `yaml:"hash" json:"hash"`
+	NumTxns      uint64        `bin:"sizeof=Transactions" yaml:"-" json:"-"`
+	Transactions []Transaction `yaml:"transactions" json:"transactions"`
+}
+
+// IsTick returns whether the entry is a tick, i.e. it contains no transactions.
+//
+// Matches Entry::is_tick of the validator. Ticks normally have a nonzero NumHashes.
+func (e *Entry) IsTick() bool {
+	return len(e.Transactions) == 0
+}
+
+// Signatures returns the first signature of each transaction in the entry.
+// Transactions without signatures are skipped.
+func (e *Entry) Signatures() []solana.Signature {
+	return appendSignatures(nil, e.Transactions)
+}
+
+func appendSignatures(sigs []solana.Signature, txns []Transaction) []solana.Signature {
+	for i := range txns {
+		if len(txns[i].Signatures) > 0 {
+			sigs = append(sigs, txns[i].Signatures[0])
+		}
+	}
+	return sigs
+}
+
+// IndexedTransaction is a transaction along with its position in the block.
+type IndexedTransaction struct {
+	Index int // position across all entries of the block
+	Tx    Transaction
+}
+
+// IndexTransactions flattens the transactions of a block's entries,
+// numbering them in block order as the validator does.
+func IndexTransactions(entries []Entry) []IndexedTransaction {
+	var txns []IndexedTransaction
+	for i := range entries {
+		for j := range entries[i].Transactions {
+			txns = append(txns, IndexedTransaction{Index: len(txns), Tx: entries[i].Transactions[j]})
+		}
+	}
+	return txns
+}
+
+// CountTicks returns the number of ticks in a list of entries.
+func CountTicks(entries []Entry) (n uint64) {
+	for i := range entries {
+		if entries[i].IsTick() {
+			n++
+		}
+	}
+	return
+}
+
+// TransactionStatusIndexMeta describes one of the primary indexes of CfTransactionStatus.
+type TransactionStatusIndexMeta struct {
+	MaxSlot uint64 `yaml:"max_slot"`
+	Frozen  bool   `yaml:"frozen"`
+}
+
+// TransactionStatusMeta holds the execution result of a transaction.
+type TransactionStatusMeta struct {
+	Err                  []byte              `yaml:"err,omitempty" json:"-"` // bincode TransactionError, nil on success
+	Fee                  uint64              `yaml:"fee" json:"fee"`
+	PreBalances          []uint64            `yaml:"pre_balances" json:"preBalances"`
+	PostBalances         []uint64            `yaml:"post_balances" json:"postBalances"`
+	InnerInstructions    []InnerInstructions `yaml:"inner_instructions" json:"innerInstructions"` // nil if not recorded
+	LogMessages          []string            `yaml:"log_messages" json:"logMessages"`             // nil if not recorded
+	PreTokenBalances     []TokenBalance      `yaml:"pre_token_balances" json:"preTokenBalances"`
+	PostTokenBalances    []TokenBalance      `yaml:"post_token_balances" json:"postTokenBalances"`
+	Rewards              []Reward            `yaml:"rewards" json:"rewards"`
+	LoadedAddresses      LoadedAddresses     `yaml:"loaded_addresses" json:"loadedAddresses"`
+	ReturnData           *ReturnData         `yaml:"return_data" json:"returnData,omitempty"`                      // nil if not recorded
+	ComputeUnitsConsumed *uint64             `yaml:"compute_units_consumed" json:"computeUnitsConsumed,omitempty"` // nil if not recorded
+}
+
+// InnerInstructions are the instructions invoked via CPI by a top-level instruction.
+type InnerInstructions struct {
+	Index        uint8              `yaml:"index" json:"index"` // index of the top-level instruction
+	Instructions []InnerInstruction `yaml:"instructions" json:"instructions"`
+}
+
+// InnerInstruction is an instruction invoked via CPI.
+type InnerInstruction struct {
+	ProgramIDIndex uint16  `yaml:"program_id_index"`
+	Accounts       []uint8 `yaml:"accounts"`
+	Data           []byte  `yaml:"data"`
+	StackHeight    *uint32 `yaml:"stack_height"` // nil if not recorded
+}
+
+// TokenBalance is the balance of an SPL token account.
+type TokenBalance struct {
+	AccountIndex  uint8            `yaml:"account_index"`
+	Mint          solana.PublicKey `yaml:"mint"`
+	UiTokenAmount UiTokenAmount    `yaml:"ui_token_amount"`
+	Owner         solana.PublicKey `yaml:"owner"`      // zero if not recorded
+	ProgramID     solana.PublicKey `yaml:"program_id"` // zero if not recorded
+}
+
+// UiTokenAmount is a token amount along with its decimal representation.
+type UiTokenAmount struct {
+	UiAmount       float64 `yaml:"ui_amount"` // deprecated, lossy
+	Decimals       uint8   `yaml:"decimals"`
+	Amount         string  `yaml:"amount"` // raw amount as a decimal integer
+	UiAmountString string  `yaml:"ui_amount_string"`
+}
+
+// ReturnData is the data returned by the last instruction of a transaction setting it.
+type ReturnData struct {
+	ProgramID solana.PublicKey `yaml:"program_id"`
+	Data      []byte           `yaml:"data"`
+}
+
+// ConfirmedTransaction is a rooted transaction along with its status meta.
+type ConfirmedTransaction struct {
+	Slot        uint64
+	Transaction Transaction
+	Meta        *TransactionStatusMeta
+}
+
+// RewardType is the kind of a reward.
+type RewardType uint8
+
+const (
+	RewardTypeUnspecified RewardType = iota
+	RewardTypeFee
+	RewardTypeRent
+	RewardTypeStaking
+	RewardTypeVoting
+)
+
+func (t RewardType) String() string {
+	switch t {
+	case RewardTypeFee:
+		return "Fee"
+	case RewardTypeRent:
+		return "Rent"
+	case RewardTypeStaking:
+		return "Staking"
+	case RewardTypeVoting:
+		return "Voting"
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON encodes the reward type as its name like the JSON-RPC API,
+// or null if unspecified or unknown.
+func (t RewardType) MarshalJSON() ([]byte, error) {
+	name := t.String()
+	if name == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(name)
+}
+
+// Reward is a balance change credited to an account at the end of a block.
+type Reward struct {
+	Pubkey      solana.PublicKey `yaml:"pubkey" json:"pubkey"`
+	Lamports    int64            `yaml:"lamports" json:"lamports"`
+	PostBalance uint64           `yaml:"post_balance" json:"postBalance"`
+	RewardType  RewardType       `yaml:"reward_type" json:"rewardType"`
+	Commission  *uint8           `yaml:"commission" json:"commission"` // only set for staking and voting rewards
+}
+
+// ProgramCost is the cost model's learned compute unit cost of a program.
+type ProgramCost struct {
+	Cost uint64 `yaml:"cost"`
+}
+
+// OptimisticSlotMeta records the bank hash of an optimistically confirmed slot.
+type OptimisticSlotMeta struct {
+	Slot      uint64      `bin:"-" yaml:"-"`
+	Version   uint32      `yaml:"-"` // enum discriminant, only V0 exists
+	Hash      solana.Hash `yaml:"hash"`
+	Timestamp int64       `yaml:"timestamp"`
+}
+
+// MerkleRootMeta tracks the first received Merkle root of an erasure set.
+type MerkleRootMeta struct {
+	MerkleRoot              *solana.Hash `yaml:"merkle_root"` // nil for legacy shreds
+	FirstReceivedShredIndex uint32       `yaml:"first_received_shred_index"`
+	FirstReceivedShredType  uint8        `yaml:"first_received_shred_type"` // shred.LegacyDataID or shred.LegacyCodeID
+}
+
+func (m *MerkleRootMeta) UnmarshalWithDecoder(dec *bin.Decoder) (err error) {
+	isSome, err := dec.ReadUint8()
+	if err != nil {
+		return err
+	}
+	switch isSome {
+	case 0:
+		m.MerkleRoot = nil
+	case 1:
+		root, err := dec.ReadNBytes(32)
+		if err != nil {
+			return err
+		}
+		m.MerkleRoot = new(solana.Hash)
+		copy(m.MerkleRoot[:], root)
+	default:
+		return fmt.Errorf("invalid option tag %d", isSome)
+	}
+	if m.FirstReceivedShredIndex, err = dec.ReadUint32(bin.LE); err != nil {
+		return err
+	}
+	m.FirstReceivedShredType, err = dec.ReadUint8()
+	return err
+}
+
+// ErasureMeta describes an erasure set (FEC set) of shreds.
+type ErasureMeta struct {
+	SetIndex         uint64        `yaml:"set_index"`
+	FirstCodingIndex uint64        `yaml:"first_coding_index"`
+	Unused           uint64        `yaml:"-"` // formerly size, first_received_coding_index in newer versions
+	Config           ErasureConfig `yaml:"config"`
+}
+
+// ErasureConfig is the number of data and coding shreds in an erasure set.
+type ErasureConfig struct {
+	NumData   uint64 `yaml:"num_data"`
+	NumCoding uint64 `yaml:"num_coding"`
+}
+
+func (c ErasureConfig) shredConfig() shred.ErasureConfig {
+	return shred.ErasureConfig{NumData: int(c.NumData), NumCoding: int(c.NumCoding)}
+}
+
+// AddressSignature references a transaction that loaded an address.
+type AddressSignature struct {
+	Slot      uint64           `yaml:"slot" json:"slot"`
+	Signature solana.Signature `yaml:"signature" json:"signature"`
+	Writeable bool             `yaml:"writeable" json:"writeable"`
 }
@@ -0,0 +1,284 @@
+package blockstore
+
+import (
+	"errors"
+	"fmt"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+)
+
+// Transaction is a legacy or v0 transaction.
+//
+// solana-go only understands legacy messages,
+// so the v0 prefix and address table lookups are handled here.
+type Transaction struct {
+	solana.Transaction
+
+	// Versioned is set for v0 messages.
+	Versioned bool `json:"versioned"`
+
+	// AddressTableLookups of a v0 message.
+	AddressTableLookups []MessageAddressTableLookup `json:"addressTableLookups,omitempty"`
+
+	// LoadedAddresses is filled by ResolveLookups.
+	LoadedAddresses *LoadedAddresses `json:"loadedAddresses,omitempty"`
+}
+
+// MessageAddressTableLookup loads accounts from an address lookup table.
+type MessageAddressTableLookup struct {
+	AccountKey      solana.PublicKey `json:"accountKey"`
+	WritableIndexes []uint8          `json:"writableIndexes"`
+	ReadonlyIndexes []uint8          `json:"readonlyIndexes"`
+}
+
+// LoadedAddresses are the accounts loaded via address lookup tables.
+type LoadedAddresses struct {
+	Writable []solana.PublicKey `json:"writable"`
+	Readonly []solana.PublicKey `json:"readonly"`
+}
+
+// Bit set in the first message byte of versioned messages.
+const messageVersionPrefix = 0x80
+
+var ErrUnresolvedLookups = errors.New("address table lookups not resolved")
+
+func (tx *Transaction) UnmarshalWithDecoder(dec *bin.Decoder) error {
+	numSignatures, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	tx.Signatures = make([]solana.Signature, numSignatures)
+	for i := range tx.Signatures {
+		sig, err := dec.ReadNBytes(64)
+		if err != nil {
+			return err
+		}
+		copy(tx.Signatures[i][:], sig)
+	}
+
+	// Legacy messages start with the number of required signatures,
+	// which never has the high bit set.
+	prefix, err := dec.Peek(1)
+	if err != nil {
+		return err
+	}
+	if prefix[0]&messageVersionPrefix != 0 {
+		if version := prefix[0] &^ messageVersionPrefix; version != 0 {
+			return fmt.Errorf("unsupported message version %d", version)
+		}
+		tx.Versioned = true
+		if _, err := dec.ReadUint8(); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Message.UnmarshalWithDecoder(dec); err != nil {
+		return err
+	}
+	if !tx.Versioned {
+		return nil
+	}
+
+	numLookups, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	tx.AddressTableLookups = make([]MessageAddressTableLookup, numLookups)
+	for i := range tx.AddressTableLookups {
+		lookup := &tx.AddressTableLookups[i]
+		key, err := dec.ReadNBytes(32)
+		if err != nil {
+			return err
+		}
+		copy(lookup.AccountKey[:], key)
+		if lookup.WritableIndexes, err = readCompactBytes(dec); err != nil {
+			return err
+		}
+		if lookup.ReadonlyIndexes, err = readCompactBytes(dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipTransaction advances the decoder past a serialized transaction without decoding it.
+func skipTransaction(dec *bin.Decoder) error {
+	numSignatures, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	if err := dec.SkipBytes(uint(numSignatures) * 64); err != nil {
+		return err
+	}
+	prefix, err := dec.Peek(1)
+	if err != nil {
+		return err
+	}
+	versioned := prefix[0]&messageVersionPrefix != 0
+	if versioned {
+		if version := prefix[0] &^ messageVersionPrefix; version != 0 {
+			return fmt.Errorf("unsupported message version %d", version)
+		}
+		if err := dec.SkipBytes(1); err != nil {
+			return err
+		}
+	}
+
+	// Message header
+	if err := dec.SkipBytes(3); err != nil {
+		return err
+	}
+	numKeys, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	// Account keys and recent blockhash
+	if err := dec.SkipBytes(uint(numKeys)*32 + 32); err != nil {
+		return err
+	}
+	numInstructions, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < numInstructions; i++ {
+		// Program ID index, account indexes and data
+		if err := dec.SkipBytes(1); err != nil {
+			return err
+		}
+		if err := skipCompactBytes(dec); err != nil {
+			return err
+		}
+		if err := skipCompactBytes(dec); err != nil {
+			return err
+		}
+	}
+	if !versioned {
+		return nil
+	}
+
+	numLookups, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < numLookups; i++ {
+		if err := dec.SkipBytes(32); err != nil {
+			return err
+		}
+		if err := skipCompactBytes(dec); err != nil {
+			return err
+		}
+		if err := skipCompactBytes(dec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func skipCompactBytes(dec *bin.Decoder) error {
+	n, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return err
+	}
+	return dec.SkipBytes(uint(n))
+}
+
+func readCompactBytes(dec *bin.Decoder) ([]byte, error) {
+	n, err := dec.ReadCompactU16Length()
+	if err != nil {
+		return nil, err
+	}
+	b, err := dec.ReadNBytes(n)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, b...), nil
+}
+
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	var buf []byte
+	bin.EncodeCompactU16Length(&buf, len(tx.Signatures))
+	for _, sig := range tx.Signatures {
+		buf = append(buf, sig[:]...)
+	}
+	if !tx.Versioned {
+		return append(buf, message...), nil
+	}
+	buf = append(buf, messageVersionPrefix)
+	buf = append(buf, message...)
+	bin.EncodeCompactU16Length(&buf, len(tx.AddressTableLookups))
+	for _, lookup := range tx.AddressTableLookups {
+		buf = append(buf, lookup.AccountKey[:]...)
+		bin.EncodeCompactU16Length(&buf, len(lookup.WritableIndexes))
+		buf = append(buf, lookup.WritableIndexes...)
+		bin.EncodeCompactU16Length(&buf, len(lookup.ReadonlyIndexes))
+		buf = append(buf, lookup.ReadonlyIndexes...)
+	}
+	return buf, nil
+}
+
+func (tx Transaction) MarshalWithEncoder(enc *bin.Encoder) error {
+	out, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return enc.WriteBytes(out, false)
+}
+
+// ResolveLookups loads the addresses referenced by the address table lookups
+// of a v0 transaction into tx.LoadedAddresses.
+//
+// resolver returns the addresses stored in a lookup table account.
+// Legacy transactions are left unchanged.
+func (tx *Transaction) ResolveLookups(resolver func(table solana.PublicKey) ([]solana.PublicKey, error)) error {
+	if !tx.Versioned {
+		return nil
+	}
+	loaded := new(LoadedAddresses)
+	for _, lookup := range tx.AddressTableLookups {
+		addresses, err := resolver(lookup.AccountKey)
+		if err != nil {
+			return fmt.Errorf("cannot resolve lookup table %s: %w", lookup.AccountKey, err)
+		}
+		if loaded.Writable, err = appendLookups(loaded.Writable, addresses, lookup.WritableIndexes); err != nil {
+			return fmt.Errorf("invalid lookup in table %s: %w", lookup.AccountKey, err)
+		}
+		if loaded.Readonly, err = appendLookups(loaded.Readonly, addresses, lookup.ReadonlyIndexes); err != nil {
+			return fmt.Errorf("invalid lookup in table %s: %w", lookup.AccountKey, err)
+		}
+	}
+	tx.LoadedAddresses = loaded
+	return nil
+}
+
+func appendLookups(list []solana.PublicKey, addresses []solana.PublicKey, indexes []uint8) ([]solana.PublicKey, error) {
+	for _, index := range indexes {
+		if int(index) >= len(addresses) {
+			return nil, fmt.Errorf("index %d out of bounds (table size %d)", index, len(addresses))
+		}
+		list = append(list, addresses[index])
+	}
+	return list, nil
+}
+
+// AccountKeys returns all accounts referenced by the transaction's instructions:
+// the static account keys, followed by writable and readonly loaded addresses.
+//
+// Returns ErrUnresolvedLookups if a v0 transaction was not passed to ResolveLookups.
+func (tx *Transaction) AccountKeys() ([]solana.PublicKey, error) {
+	if !tx.Versioned || len(tx.AddressTableLookups) == 0 {
+		return tx.Message.AccountKeys, nil
+	}
+	if tx.LoadedAddresses == nil {
+		return nil, ErrUnresolvedLookups
+	}
+	keys := make([]solana.PublicKey, 0, len(tx.Message.AccountKeys)+len(tx.LoadedAddresses.Writable)+len(tx.LoadedAddresses.Readonly))
+	keys = append(keys, tx.Message.AccountKeys...)
+	keys = append(keys, tx.LoadedAddresses.Writable...)
+	keys = append(keys, tx.LoadedAddresses.Readonly...)
+	return keys, nil
+}